@@ -0,0 +1,76 @@
+package mock
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+type Responder func(request IncomingRequest) ResponseBuilder
+
+type IncomingRequest struct {
+	Method          string
+	URL             string
+	Headers         http.Header
+	QueryParameters url.Values
+	Cookies         map[string]string
+	PathParameters  map[string]string
+	Body            []byte
+}
+
+type callback struct {
+	responder Responder
+	handler   http.Handler
+}
+
+func (c *callback) bind(request httpRequest) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, httpRequest *http.Request) {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				LogError("the callback responder panicked, error: %v", recovered)
+				writeErrorAsJson(callbackError(fmt.Errorf("%v", recovered)), writer)
+			}
+		}()
+		if c.handler != nil {
+			c.handler.ServeHTTP(writer, httpRequest)
+			return
+		}
+		response, err := c.respond(request)
+		if err != nil {
+			writeErrorAsJson(err, writer)
+			return
+		}
+		writeHttpResponse(httpRequest.Context(), writer, response)
+	})
+}
+
+func (c *callback) respond(request httpRequest) (*httpResponse, error) {
+	builder := c.responder(request.toIncoming())
+	if builder == nil {
+		return nil, callbackError(fmt.Errorf("the responder returned a null response"))
+	}
+	dto := builder.Build()
+	if dto.Status == 0 && dto.Fault == "" {
+		return nil, callbackError(fmt.Errorf("the response status is required"))
+	}
+	response, err := dto.toHttpResponse()
+	if err != nil {
+		return nil, callbackError(err)
+	}
+	if response.Template != nil {
+		return response.Template.render(*response, request)
+	}
+	return response, nil
+}
+
+func (request httpRequest) toIncoming() IncomingRequest {
+	return IncomingRequest{
+		Method:          request.Method,
+		URL:             request.URL,
+		Headers:         http.Header(multiValues(request.Headers, request.HeaderValues)),
+		QueryParameters: url.Values(multiValues(request.QueryParameters, request.QueryValues)),
+		Cookies:         request.Cookies,
+		PathParameters:  request.PathParameters,
+		Body:            request.Body,
+	}
+}
@@ -0,0 +1,74 @@
+package mock
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIncomingRequest(t *testing.T) {
+	request := httpRequest{
+		URL:             "/users/1",
+		Method:          getMethod,
+		Headers:         map[string]string{"Accept": "application/json"},
+		QueryParameters: map[string]string{"tag": "a"},
+		QueryValues:     map[string][]string{"tag": {"a", "b"}},
+		Cookies:         map[string]string{"session": "abc"},
+		PathParameters:  map[string]string{"id": "1"},
+		Body:            []byte(`{}`),
+	}
+	incoming := request.toIncoming()
+	assert.Equal(t, getMethod, incoming.Method)
+	assert.Equal(t, "/users/1", incoming.URL)
+	assert.Equal(t, "application/json", incoming.Headers.Get("Accept"))
+	assert.Equal(t, []string{"a", "b"}, incoming.QueryParameters["tag"])
+	assert.Equal(t, "abc", incoming.Cookies["session"])
+	assert.Equal(t, "1", incoming.PathParameters["id"])
+	assert.Equal(t, []byte(`{}`), incoming.Body)
+}
+
+func TestCallbackRespondWithTemplate(t *testing.T) {
+	responder := &callback{responder: func(request IncomingRequest) ResponseBuilder {
+		return Response().WithStatus(http.StatusOK).WithBodyAsString(`{{.Request.Method}}`).WithTemplate()
+	}}
+	response, err := responder.respond(httpRequest{Method: getMethod})
+	assert.Nil(t, err)
+	assert.Equal(t, []byte(getMethod), response.Body)
+}
+
+func TestCallbackRespondWithInvalidResponse(t *testing.T) {
+	responder := &callback{responder: func(request IncomingRequest) ResponseBuilder {
+		return Response().WithStatus(http.StatusOK).WithBodyAsString(`{{`).WithTemplate()
+	}}
+	_, err := responder.respond(httpRequest{})
+	assert.Error(t, err)
+	assert.Equal(t, callbackErrorCode, err.(Error).Code)
+}
+
+func TestCallbackRespondWithoutStatus(t *testing.T) {
+	responder := &callback{responder: func(request IncomingRequest) ResponseBuilder {
+		return Response().WithBodyAsString("x")
+	}}
+	_, err := responder.respond(httpRequest{})
+	require.Error(t, err)
+	assert.Equal(t, callbackErrorCode, err.(Error).Code)
+	assert.Equal(t, "error executing the callback responder: the response status is required", err.(Error).Description)
+	responder = &callback{responder: func(request IncomingRequest) ResponseBuilder {
+		return Response().WithFault(FaultEmptyResponse)
+	}}
+	response, err := responder.respond(httpRequest{})
+	assert.Nil(t, err)
+	assert.Equal(t, FaultEmptyResponse, response.Fault)
+}
+
+func TestCallbackBindHandler(t *testing.T) {
+	responder := &callback{handler: http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusTeapot)
+	})}
+	recorder := httptest.NewRecorder()
+	responder.bind(httpRequest{}).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusTeapot, recorder.Code)
+}
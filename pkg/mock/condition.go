@@ -0,0 +1,292 @@
+package mock
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+type conditionDTO map[string]any
+
+func EqualTo(value string) conditionDTO {
+	return conditionDTO{operatorEqual: value}
+}
+
+func Contains(value string) conditionDTO {
+	return conditionDTO{operatorContains: value}
+}
+
+func PatternIs(pattern string) conditionDTO {
+	return conditionDTO{operatorPattern: pattern}
+}
+
+func EqualToIgnoringCase(value string) conditionDTO {
+	return conditionDTO{operatorEqualIgnoreCase: value}
+}
+
+func ContainsIgnoringCase(value string) conditionDTO {
+	return conditionDTO{operatorContainsIgnoreCase: value}
+}
+
+func HasItem(value string) conditionDTO {
+	return conditionDTO{operatorHasItem: value}
+}
+
+func IncludesAll(values ...string) conditionDTO {
+	return conditionDTO{operatorIncludesAll: values}
+}
+
+func ExactList(values ...string) conditionDTO {
+	return conditionDTO{operatorExactList: values}
+}
+
+func EqualToJSON(value string) conditionDTO {
+	return conditionDTO{operatorEqualToJSON: value}
+}
+
+func EqualToXML(value string) conditionDTO {
+	return conditionDTO{operatorEqualToXML: value}
+}
+
+func Absent() conditionDTO {
+	return conditionDTO{operatorAbsent: ""}
+}
+
+func Present() conditionDTO {
+	return conditionDTO{operatorPresent: ""}
+}
+
+func And(conditions ...conditionDTO) conditionDTO {
+	return conditionDTO{operatorAnd: conditions}
+}
+
+func Or(conditions ...conditionDTO) conditionDTO {
+	return conditionDTO{operatorOr: conditions}
+}
+
+func Not(condition conditionDTO) conditionDTO {
+	return conditionDTO{operatorNot: condition}
+}
+
+func (c *conditionDTO) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	var list []conditionDTO
+	if err := json.Unmarshal(data, &list); err == nil {
+		*c = conditionDTO{operatorAnd: list}
+		return nil
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	condition := conditionDTO{}
+	for key, rawValue := range raw {
+		var value any
+		switch key {
+		case operatorAnd, operatorOr:
+			var nested []conditionDTO
+			if err := json.Unmarshal(rawValue, &nested); err != nil {
+				return err
+			}
+			value = nested
+		case operatorNot:
+			var nested conditionDTO
+			if err := json.Unmarshal(rawValue, &nested); err != nil {
+				return err
+			}
+			value = nested
+		default:
+			if err := json.Unmarshal(rawValue, &value); err != nil {
+				return err
+			}
+		}
+		condition[key] = value
+	}
+	*c = condition
+	return nil
+}
+
+func mergeCondition(current conditionDTO, condition conditionDTO) conditionDTO {
+	merged := conditionDTO{}
+	for key, value := range current {
+		merged[key] = value
+	}
+	for key, value := range condition {
+		existing, found := merged[key]
+		switch {
+		case !found || isConditionOption(key):
+			merged[key] = value
+		case key == operatorAnd:
+			existingConditions, _ := existing.([]conditionDTO)
+			addedConditions, _ := value.([]conditionDTO)
+			merged[key] = append(append([]conditionDTO{}, existingConditions...), addedConditions...)
+		default:
+			conditions, _ := merged[operatorAnd].([]conditionDTO)
+			merged[operatorAnd] = append(append([]conditionDTO{}, conditions...), conditionDTO{key: value})
+		}
+	}
+	return merged
+}
+
+func isConditionOption(key string) bool {
+	return key == optionIgnoreExtraFields || key == optionIgnoreArrayOrder
+}
+
+func buildCondition(dto conditionDTO) (*simplexCondition, error) {
+	keys := make([]string, 0, len(dto))
+	for key := range dto {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var conditions []*simplexCondition
+	for _, key := range keys {
+		condition, err := buildConditionEntry(key, dto[key])
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, condition)
+	}
+	switch len(conditions) {
+	case 0:
+		return nil, nil
+	case 1:
+		return conditions[0], nil
+	default:
+		return &simplexCondition{operator: and, conditions: conditions}, nil
+	}
+}
+
+func buildConditionEntry(key string, value any) (*simplexCondition, error) {
+	op := fromString(key)
+	switch op {
+	case undefined:
+		return nil, invalidRequest(fmt.Sprintf("the operator %s is not supported.", key))
+	case and, or:
+		nested, ok := value.([]conditionDTO)
+		if !ok || len(nested) < 1 {
+			return nil, invalidRequest(fmt.Sprintf("the operator %s requires a list of conditions.", key))
+		}
+		combined := &simplexCondition{operator: op}
+		for _, dto := range nested {
+			condition, err := buildCondition(dto)
+			if err != nil {
+				return nil, err
+			}
+			if condition == nil {
+				return nil, invalidRequest(fmt.Sprintf("the operator %s requires a list of conditions.", key))
+			}
+			combined.conditions = append(combined.conditions, condition)
+		}
+		return combined, nil
+	case not:
+		nested, _ := value.(conditionDTO)
+		condition, err := buildCondition(nested)
+		if err != nil {
+			return nil, err
+		}
+		if condition == nil {
+			return nil, invalidRequest(fmt.Sprintf("the operator %s requires a condition.", key))
+		}
+		return &simplexCondition{operator: op, conditions: []*simplexCondition{condition}}, nil
+	case absent, present:
+		if value != true && value != "" {
+			return nil, invalidRequest(fmt.Sprintf("the value of the operator %s must be true.", key))
+		}
+		return &simplexCondition{operator: op}, nil
+	case includesAll, exactList:
+		values, ok := stringList(value)
+		if !ok {
+			return nil, invalidRequest(fmt.Sprintf("the value of the operator %s must be a list of strings.", key))
+		}
+		return &simplexCondition{operator: op, values: values}, nil
+	default:
+		text, ok := value.(string)
+		if !ok {
+			return nil, invalidRequest(fmt.Sprintf("the value of the operator %s must be a string.", key))
+		}
+		return compileCondition(op, text)
+	}
+}
+
+func compileCondition(op operator, value string) (*simplexCondition, error) {
+	condition := &simplexCondition{operator: op, value: value}
+	switch op {
+	case pattern:
+		regex, err := regexp.Compile(value)
+		if err != nil {
+			return nil, invalidRequest(fmt.Sprintf("the pattern %s is invalid: %s.", value, err.Error()))
+		}
+		condition.regex = regex
+	case urlTemplateMatch:
+		template, err := parseURLTemplate(value)
+		if err != nil {
+			return nil, err
+		}
+		condition.template = template
+	case equalToJSON:
+		document, valid := parseJSON([]byte(value))
+		if !valid {
+			return nil, invalidRequest(fmt.Sprintf("the value %s of the operator %s is not a valid json.", value, operatorEqualToJSON))
+		}
+		condition.expectedJSON = &jsonDocument{value: document}
+	case equalToXML:
+		document, valid := parseXML([]byte(value))
+		if !valid {
+			return nil, invalidRequest(fmt.Sprintf("the value %s of the operator %s is not a valid xml.", value, operatorEqualToXML))
+		}
+		condition.expectedXML = document
+	}
+	return condition, nil
+}
+
+func stringList(value any) ([]string, bool) {
+	switch list := value.(type) {
+	case []string:
+		return list, true
+	case []any:
+		values := make([]string, 0, len(list))
+		for _, item := range list {
+			text, ok := item.(string)
+			if !ok {
+				return nil, false
+			}
+			values = append(values, text)
+		}
+		return values, true
+	default:
+		return nil, false
+	}
+}
+
+func (c *simplexCondition) walk(visit func(condition *simplexCondition) error) error {
+	if err := visit(c); err != nil {
+		return err
+	}
+	for _, condition := range c.conditions {
+		if err := condition.walk(visit); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c simplexCondition) describe() string {
+	switch c.operator {
+	case and, or, not:
+		descriptions := make([]string, 0, len(c.conditions))
+		for _, condition := range c.conditions {
+			descriptions = append(descriptions, condition.describe())
+		}
+		return fmt.Sprintf("%s(%s)", c.operator, strings.Join(descriptions, ", "))
+	case absent, present:
+		return c.operator.String()
+	case includesAll, exactList:
+		return fmt.Sprintf("%s %q", c.operator, c.values)
+	default:
+		return fmt.Sprintf("%s %q", c.operator, c.value)
+	}
+}
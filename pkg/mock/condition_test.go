@@ -0,0 +1,161 @@
+package mock
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func buildConditionFromJSON(t *testing.T, raw string) *simplexCondition {
+	var dto conditionDTO
+	require.Nil(t, json.Unmarshal([]byte(raw), &dto))
+	condition, err := buildCondition(dto)
+	require.Nil(t, err)
+	return condition
+}
+
+func TestConditionSeveralOperatorsAreCombinedWithAnd(t *testing.T) {
+	condition := buildConditionFromJSON(t, `{"contains":"json","pattern":"^app"}`)
+	assert.Equal(t, `and(contains "json", pattern "^app")`, condition.describe())
+	assert.True(t, condition.test("application/json"))
+	assert.False(t, condition.test("text/json"))
+	assert.False(t, condition.test("application/xml"))
+}
+
+func TestConditionList(t *testing.T) {
+	condition := buildConditionFromJSON(t, `[{"contains":"json"},{"contains":"utf-8"}]`)
+	assert.True(t, condition.test("application/json; charset=utf-8"))
+	assert.False(t, condition.test("application/json"))
+}
+
+func TestConditionNull(t *testing.T) {
+	var dto requestDTO
+	require.Nil(t, json.Unmarshal([]byte(`{"url":null,"body":null,"headers":{"Accept":null}}`), &dto))
+	assert.Nil(t, dto.URL)
+	assert.Nil(t, dto.Body)
+	assert.Nil(t, dto.Headers["Accept"])
+	condition, err := buildCondition(dto.Body)
+	assert.Nil(t, err)
+	assert.Nil(t, condition)
+}
+
+func TestConditionCombinators(t *testing.T) {
+	condition := buildConditionFromJSON(t,
+		`{"or":[{"equal_to":"/a"},{"and":[{"pattern":"^/b"},{"not":{"contains":"admin"}}]}]}`)
+	assert.Equal(t, `or(equal_to "/a", and(pattern "^/b", not(contains "admin")))`, condition.describe())
+	assert.True(t, condition.test("/a"))
+	assert.True(t, condition.test("/b/users"))
+	assert.False(t, condition.test("/b/admin"))
+	assert.False(t, condition.test("/c"))
+}
+
+func TestConditionBuilders(t *testing.T) {
+	dto := Or(EqualTo("a"), And(Contains("b"), Not(PatternIs("^x"))))
+	data, err := json.Marshal(dto)
+	require.Nil(t, err)
+	assert.JSONEq(t, `{"or":[{"equal_to":"a"},{"and":[{"contains":"b"},{"not":{"pattern":"^x"}}]}]}`, string(data))
+	var decoded conditionDTO
+	require.Nil(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, dto, decoded)
+	condition, err := buildCondition(dto)
+	require.Nil(t, err)
+	assert.True(t, condition.test("abc"))
+	assert.False(t, condition.test("xbc"))
+}
+
+func TestConditionInvalid(t *testing.T) {
+	cases := map[string]string{
+		`{"like":"a"}`:                  "the operator like is not supported.",
+		`{"or":[]}`:                     "the operator or requires a list of conditions.",
+		`{"and":[{}]}`:                  "the operator and requires a list of conditions.",
+		`{"not":{}}`:                    "the operator not requires a condition.",
+		`{"equal_to":2}`:                "the value of the operator equal_to must be a string.",
+		`{"or":[{"not":{"like":"a"}}]}`: "the operator like is not supported.",
+		`{"absent":false}`:              "the value of the operator absent must be true.",
+		`{"present":"no"}`:              "the value of the operator present must be true.",
+		`{"equal_to_json":"{bad"}`:      "the value {bad of the operator equal_to_json is not a valid json.",
+		`{"equal_to_xml":"<a>"}`:        "the value <a> of the operator equal_to_xml is not a valid xml.",
+	}
+	for raw, description := range cases {
+		var dto conditionDTO
+		require.Nil(t, json.Unmarshal([]byte(raw), &dto))
+		_, err := buildCondition(dto)
+		require.Error(t, err, raw)
+		assert.Equal(t, description, err.(Error).Description, raw)
+	}
+	var dto conditionDTO
+	assert.Error(t, json.Unmarshal([]byte(`{"or":{"equal_to":"a"}}`), &dto))
+}
+
+func TestMergeCondition(t *testing.T) {
+	merged := mergeCondition(nil, Contains("json"))
+	merged = mergeCondition(merged, PatternIs("^app"))
+	assert.Equal(t, conditionDTO{"contains": "json", "pattern": "^app"}, merged)
+	merged = mergeCondition(merged, Contains("utf"))
+	assert.Equal(t, conditionDTO{"contains": "json", "pattern": "^app", "and": []conditionDTO{{"contains": "utf"}}}, merged)
+	merged = mergeCondition(merged, And(EqualTo("x")))
+	assert.Equal(t, []conditionDTO{{"contains": "utf"}, {"equal_to": "x"}}, merged["and"])
+}
+
+func TestMultiValuedOperators(t *testing.T) {
+	params := map[string][]string{"tag": {"a", "b", "c"}}
+	cases := []struct {
+		condition conditionDTO
+		expected  bool
+	}{
+		{HasItem("b"), true},
+		{HasItem("d"), false},
+		{IncludesAll("c", "a"), true},
+		{IncludesAll("a", "d"), false},
+		{ExactList("a", "b", "c"), true},
+		{ExactList("c", "b", "a"), false},
+		{EqualTo("a,b,c"), true},
+		{Not(HasItem("d")), true},
+	}
+	for _, c := range cases {
+		condition, err := buildCondition(c.condition)
+		require.Nil(t, err)
+		complex := complexCondition{simplexCondition: *condition, field: "tag"}
+		assert.Equal(t, c.expected, complex.test(params), condition.describe())
+	}
+}
+
+func TestMultiValuedOperatorsFromJSON(t *testing.T) {
+	condition := buildConditionFromJSON(t, `{"includes_all":["a","b"],"has_item":"c"}`)
+	assert.Equal(t, `and(has_item "c", includes_all ["a" "b"])`, condition.describe())
+	assert.True(t, condition.testValues([]string{"b", "c", "a"}, true))
+	var dto conditionDTO
+	require.Nil(t, json.Unmarshal([]byte(`{"exact_list":"a"}`), &dto))
+	_, err := buildCondition(dto)
+	assert.Equal(t, "the value of the operator exact_list must be a list of strings.", err.(Error).Description)
+}
+
+func TestPatternIsCompiledOnce(t *testing.T) {
+	condition, err := buildCondition(PatternIs("^/users/[0-9]+$"))
+	require.Nil(t, err)
+	require.NotNil(t, condition.regex)
+	assert.True(t, condition.test("/users/12"))
+	assert.False(t, condition.test("/users/ab"))
+	template, err := buildCondition(conditionDTO{"url_template": "/users/{id}"})
+	require.Nil(t, err)
+	assert.Equal(t, urlTemplate{{literal: "users"}, {parameter: "id"}}, template.template)
+}
+
+func TestInvalidPatternIsRejected(t *testing.T) {
+	_, err := buildCondition(Or(EqualTo("a"), PatternIs("([a-z")))
+	require.Error(t, err)
+	assert.Equal(t, "invalid_request", err.(Error).Code)
+	assert.Equal(t, "the pattern ([a-z is invalid: error parsing regexp: missing closing ]: `[a-z`.", err.(Error).Description)
+}
+
+func TestPatternWithoutCompiledRegex(t *testing.T) {
+	condition := simplexCondition{operator: pattern, value: "(["}
+	assert.False(t, condition.test("anything"))
+	condition = simplexCondition{operator: pattern, value: ".*"}
+	assert.False(t, condition.test("anything"))
+	template := &simplexCondition{operator: urlTemplateMatch, value: "/users/{id}"}
+	assert.False(t, template.test("/users/1"))
+	assert.Nil(t, template.pathParameters("/users/1"))
+}
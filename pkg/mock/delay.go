@@ -0,0 +1,82 @@
+package mock
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"time"
+)
+
+const (
+	delayUniform   = "uniform"
+	delayLognormal = "lognormal"
+)
+
+type delayDistribution interface {
+	sample() time.Duration
+}
+
+type uniformDelay struct {
+	lower time.Duration
+	upper time.Duration
+}
+
+type lognormalDelay struct {
+	median time.Duration
+	sigma  float64
+}
+
+type chunkedDribbleDelay struct {
+	chunks int
+	total  time.Duration
+}
+
+func (d uniformDelay) sample() time.Duration {
+	return d.lower + time.Duration(rand.Int63n(int64(d.upper-d.lower)+1))
+}
+
+func (d lognormalDelay) sample() time.Duration {
+	return time.Duration(float64(d.median) * math.Exp(rand.NormFloat64()*d.sigma))
+}
+
+func (r *httpResponse) delay() time.Duration {
+	total := r.FixedDelay
+	if r.RandomDelay != nil {
+		total += r.RandomDelay.sample()
+	}
+	return total
+}
+
+func (d *chunkedDribbleDelay) split(body []byte) [][]byte {
+	chunks := d.chunks
+	if chunks > len(body) {
+		chunks = len(body)
+	}
+	if chunks < 1 {
+		return [][]byte{body}
+	}
+	size := len(body) / chunks
+	var parts [][]byte
+	for i := 0; i < chunks; i++ {
+		end := (i + 1) * size
+		if i == chunks-1 {
+			end = len(body)
+		}
+		parts = append(parts, body[i*size:end])
+	}
+	return parts
+}
+
+func sleep(ctx context.Context, duration time.Duration) bool {
+	if duration <= 0 {
+		return true
+	}
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,80 @@
+package mock
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUniformDelaySample(t *testing.T) {
+	delay := uniformDelay{lower: 10 * time.Millisecond, upper: 20 * time.Millisecond}
+	for i := 0; i < 100; i++ {
+		sample := delay.sample()
+		assert.GreaterOrEqual(t, sample, 10*time.Millisecond)
+		assert.LessOrEqual(t, sample, 20*time.Millisecond)
+	}
+}
+
+func TestUniformDelaySampleSameBounds(t *testing.T) {
+	delay := uniformDelay{lower: 10 * time.Millisecond, upper: 10 * time.Millisecond}
+	assert.Equal(t, 10*time.Millisecond, delay.sample())
+}
+
+func TestLognormalDelaySample(t *testing.T) {
+	delay := lognormalDelay{median: 50 * time.Millisecond, sigma: 0.5}
+	below := 0
+	for i := 0; i < 1000; i++ {
+		sample := delay.sample()
+		assert.Greater(t, sample, time.Duration(0))
+		if sample < 50*time.Millisecond {
+			below++
+		}
+	}
+	assert.InDelta(t, 500, below, 100)
+}
+
+func TestLognormalDelayWithoutSigma(t *testing.T) {
+	delay := lognormalDelay{median: 50 * time.Millisecond}
+	assert.Equal(t, 50*time.Millisecond, delay.sample())
+}
+
+func TestResponseDelay(t *testing.T) {
+	response := httpResponse{
+		FixedDelay:  10 * time.Millisecond,
+		RandomDelay: uniformDelay{lower: 5 * time.Millisecond, upper: 5 * time.Millisecond},
+	}
+	assert.Equal(t, 15*time.Millisecond, response.delay())
+	assert.Equal(t, time.Duration(0), (&httpResponse{}).delay())
+}
+
+func TestChunkedDribbleSplit(t *testing.T) {
+	dribble := chunkedDribbleDelay{chunks: 3}
+	assert.Equal(t, [][]byte{[]byte("ab"), []byte("cd"), []byte("efg")}, dribble.split([]byte("abcdefg")))
+}
+
+func TestChunkedDribbleSplitMoreChunksThanBytes(t *testing.T) {
+	dribble := chunkedDribbleDelay{chunks: 5}
+	assert.Equal(t, [][]byte{[]byte("a"), []byte("b")}, dribble.split([]byte("ab")))
+}
+
+func TestChunkedDribbleSplitEmptyBody(t *testing.T) {
+	dribble := chunkedDribbleDelay{chunks: 5}
+	assert.Equal(t, [][]byte{nil}, dribble.split(nil))
+}
+
+func TestSleep(t *testing.T) {
+	start := time.Now()
+	assert.True(t, sleep(context.Background(), 20*time.Millisecond))
+	assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+	assert.True(t, sleep(context.Background(), 0))
+}
+
+func TestSleepCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	start := time.Now()
+	assert.False(t, sleep(ctx, time.Second))
+	assert.Less(t, time.Since(start), time.Second)
+}
@@ -0,0 +1,81 @@
+package mock
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const maxNearMisses = 3
+
+type fieldMatch struct {
+	Field   string `json:"field"`
+	Matched bool   `json:"matched"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+type nearMiss struct {
+	MappingID string       `json:"mapping_id"`
+	Matched   int          `json:"matched"`
+	Total     int          `json:"total"`
+	Fields    []fieldMatch `json:"fields"`
+}
+
+func findNearMisses(aggregates []mock, request httpRequest, scenarios Scenarios) []nearMiss {
+	var nearMisses []nearMiss
+	for _, aggregate := range aggregates {
+		fields := aggregate.Request.evaluate(request)
+		if aggregate.Scenario != nil && aggregate.Scenario.requiredState != "" {
+			fields = append(fields, evaluateScenario(aggregate.Scenario, scenarios))
+		}
+		matched := 0
+		for _, field := range fields {
+			if field.Matched {
+				matched++
+			}
+		}
+		nearMisses = append(nearMisses, nearMiss{
+			MappingID: aggregate.ID,
+			Matched:   matched,
+			Total:     len(fields),
+			Fields:    fields,
+		})
+	}
+	sort.SliceStable(nearMisses, func(i, j int) bool {
+		return nearMisses[i].distance() < nearMisses[j].distance() ||
+			nearMisses[i].distance() == nearMisses[j].distance() && nearMisses[i].Matched > nearMisses[j].Matched
+	})
+	if len(nearMisses) > maxNearMisses {
+		nearMisses = nearMisses[:maxNearMisses]
+	}
+	return nearMisses
+}
+
+func evaluateScenario(sc *scenario, scenarios Scenarios) fieldMatch {
+	field := fmt.Sprintf("scenario %s", sc.name)
+	result := fieldMatch{Field: field, Matched: sc.isActive(scenarios)}
+	if !result.Matched {
+		state, _ := scenarios.State(sc.name)
+		result.Reason = fmt.Sprintf("%s state %q is not %q", field, state, sc.requiredState)
+	}
+	return result
+}
+
+func (n nearMiss) distance() float64 {
+	if n.Total == 0 {
+		return 1
+	}
+	return float64(n.Total-n.Matched) / float64(n.Total)
+}
+
+func (n nearMiss) String() string {
+	var parts []string
+	for _, field := range n.Fields {
+		if field.Matched {
+			parts = append(parts, fmt.Sprintf("%s matched", field.Field))
+		} else {
+			parts = append(parts, field.Reason)
+		}
+	}
+	return fmt.Sprintf("mapping %s (%d/%d): %s", n.MappingID, n.Matched, n.Total, strings.Join(parts, ", "))
+}
@@ -0,0 +1,68 @@
+package mock
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindNearMissesOrderedByDistance(t *testing.T) {
+	method := getMethod
+	aggregates := []mock{
+		{
+			ID: "far",
+			Request: requestMatch{
+				URL:    &simplexCondition{operator: equal, value: "/other"},
+				Method: &method,
+			},
+		},
+		{
+			ID: "close",
+			Request: requestMatch{
+				URL:    &simplexCondition{operator: equal, value: "/users"},
+				Method: &method,
+			},
+		},
+	}
+	nearMisses := findNearMisses(aggregates, httpRequest{URL: "/users", Method: postMethod}, newScenarios())
+	assert.Equal(t, 2, len(nearMisses))
+	assert.Equal(t, "close", nearMisses[0].MappingID)
+	assert.Equal(t, 1, nearMisses[0].Matched)
+	assert.Equal(t, 2, nearMisses[0].Total)
+	assert.Equal(t, "far", nearMisses[1].MappingID)
+	assert.Equal(t, 0, nearMisses[1].Matched)
+}
+
+func TestFindNearMissesLimit(t *testing.T) {
+	var aggregates []mock
+	for _, id := range []string{"1", "2", "3", "4", "5"} {
+		aggregates = append(aggregates, mock{
+			ID: id,
+			Request: requestMatch{
+				URL: &simplexCondition{operator: equal, value: "/other"},
+			},
+		})
+	}
+	nearMisses := findNearMisses(aggregates, httpRequest{URL: "/users"}, newScenarios())
+	assert.Equal(t, maxNearMisses, len(nearMisses))
+}
+
+func TestFindNearMissesEmpty(t *testing.T) {
+	assert.Empty(t, findNearMisses(nil, httpRequest{URL: "/users"}, newScenarios()))
+}
+
+func TestNearMissString(t *testing.T) {
+	method := getMethod
+	aggregate := mock{
+		ID: "1",
+		Request: requestMatch{
+			URL:    &simplexCondition{operator: equal, value: "/users"},
+			Method: &method,
+			Headers: complexConditions{
+				{simplexCondition: simplexCondition{operator: contains, value: "json"}, field: "Accept"},
+			},
+		},
+	}
+	nearMisses := findNearMisses([]mock{aggregate}, httpRequest{URL: "/users", Method: postMethod}, newScenarios())
+	assert.Equal(t, `mapping 1 (1/3): url matched, method POST is not GET, header Accept is missing`, nearMisses[0].String())
+}
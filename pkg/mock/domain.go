@@ -1,8 +1,13 @@
 package mock
 
 import (
+	"fmt"
+	"net/http"
 	"regexp"
+	"slices"
 	"strings"
+	"sync/atomic"
+	"time"
 )
 
 const (
@@ -10,17 +15,40 @@ const (
 	equal
 	contains
 	pattern
+	exists
+	equalToJSON
+	equalToXML
+	and
+	or
+	not
+	absent
+	present
+	equalIgnoreCase
+	containsIgnoreCase
+	hasItem
+	includesAll
+	exactList
+	urlTemplateMatch
 )
 
 type biPredicate[T comparable, Z comparable] func(T, Z) bool
 
 type complexConditions []complexCondition
 
+type fieldVisitor func(field string, matched bool, reason func() string) bool
+
 type operator uint8
 
 type simplexCondition struct {
-	operator operator
-	value    string
+	operator     operator
+	value        string
+	jsonOptions  jsonEqualityOptions
+	conditions   []*simplexCondition
+	values       []string
+	regex        *regexp.Regexp
+	template     urlTemplate
+	expectedJSON *jsonDocument
+	expectedXML  *xmlNode
 }
 
 type complexCondition struct {
@@ -28,19 +56,47 @@ type complexCondition struct {
 	field string
 }
 
+type jsonPathConditions []jsonPathCondition
+
+type jsonPathCondition struct {
+	complexCondition
+	path jsonPath
+}
+
+type xPathConditions []xPathCondition
+
+type xPathCondition struct {
+	complexCondition
+	path xPath
+}
+
 type mock struct {
-	ID       string       `json:"id"`
-	Request  requestMatch `json:"request"`
-	Response httpResponse `json:"response"`
+	ID         string            `json:"id"`
+	Request    requestMatch      `json:"request"`
+	Response   httpResponse      `json:"response"`
+	Scenario   *scenario         `json:"-"`
+	Sequence   *responseSequence `json:"-"`
+	definition mockDTO
+	sequence   uint64
+}
+
+type responseSequence struct {
+	responses []httpResponse
+	loop      bool
+	calls     *atomic.Uint64
 }
 
 type requestMatch struct {
-	URL             *simplexCondition `json:"url"`
-	Method          *string           `json:"method"`
-	Headers         complexConditions `json:"headers"`
-	QueryParameters complexConditions `json:"query_parameters"`
-	Body            *simplexCondition `json:"body"`
-	Priority        int               `json:"priority"`
+	URL             *simplexCondition  `json:"url"`
+	Method          *string            `json:"method"`
+	Headers         complexConditions  `json:"headers"`
+	QueryParameters complexConditions  `json:"query_parameters"`
+	Cookies         complexConditions  `json:"cookies"`
+	PathParameters  complexConditions  `json:"path_parameters"`
+	Body            *simplexCondition  `json:"body"`
+	BodyJSONPath    jsonPathConditions `json:"body_json_path"`
+	BodyXPath       xPathConditions    `json:"body_xpath"`
+	Priority        int                `json:"priority"`
 }
 
 type httpRequest struct {
@@ -48,62 +104,294 @@ type httpRequest struct {
 	Method          string
 	Headers         map[string]string
 	QueryParameters map[string]string
+	Cookies         map[string]string
 	Body            []byte
+	HeaderValues    map[string][]string
+	QueryValues     map[string][]string
+	PathParameters  map[string]string
 }
 
 type httpResponse struct {
-	Status  int               `json:"status"`
-	Body    []byte            `json:"body"`
-	Headers map[string]string `json:"headers"`
+	Status         int                  `json:"status"`
+	Body           []byte               `json:"body"`
+	Headers        map[string]string    `json:"headers"`
+	FixedDelay     time.Duration        `json:"-"`
+	RandomDelay    delayDistribution    `json:"-"`
+	ChunkedDribble *chunkedDribbleDelay `json:"-"`
+	Fault          Fault                `json:"-"`
+	Template       *responseTemplate    `json:"-"`
+	callback       *callback
+	handler        http.Handler
 }
 
-func equalsPredicate(value string, toCompare string) bool {
-	return value == toCompare
+func newResponseSequence(responses []httpResponse, loop bool) *responseSequence {
+	return &responseSequence{
+		responses: responses,
+		loop:      loop,
+		calls:     &atomic.Uint64{},
+	}
+}
+
+func (m *mock) nextResponse() *httpResponse {
+	if m.Sequence == nil {
+		return &m.Response
+	}
+	return m.Sequence.next()
 }
 
-func regexPredicate(value string, toCompare string) bool {
-	reg, _ := regexp.Compile(value)
-	return reg.MatchString(toCompare)
+func (sequence *responseSequence) next() *httpResponse {
+	size := uint64(len(sequence.responses))
+	index := sequence.calls.Add(1) - 1
+	if sequence.loop {
+		index = index % size
+	} else if index >= size {
+		index = size - 1
+	}
+	return &sequence.responses[index]
+}
+
+func equalsPredicate(value string, toCompare string) bool {
+	return value == toCompare
 }
 
 func containsPredicate(value string, toCompare string) bool {
 	return strings.Contains(toCompare, value)
 }
 
+func equalsIgnoreCasePredicate(value string, toCompare string) bool {
+	return strings.EqualFold(value, toCompare)
+}
+
+func containsIgnoreCasePredicate(value string, toCompare string) bool {
+	return strings.Contains(strings.ToLower(toCompare), strings.ToLower(value))
+}
+
+func existsPredicate(_ string, _ string) bool {
+	return true
+}
+
 func (match *requestMatch) IsExpected(request httpRequest) bool {
-	urlMatch := true
-	if match.URL != nil {
-		urlMatch = match.URL.test(request.URL)
+	return match.check(request, matchedOnly)
+}
+
+func (match *requestMatch) evaluate(request httpRequest) []fieldMatch {
+	var results []fieldMatch
+	match.check(request, collect(&results))
+	return results
+}
+
+func (match *requestMatch) check(request httpRequest, visit fieldVisitor) bool {
+	if match.URL != nil && !checkSimplex("url", match.URL, request.URL, visit) {
+		return false
 	}
-	methodMatch := true
 	if match.Method != nil {
-		methodMatch = *match.Method == request.Method
+		reason := func() string { return fmt.Sprintf("method %s is not %s", request.Method, *match.Method) }
+		if !visit("method", *match.Method == request.Method, reason) {
+			return false
+		}
+	}
+	if len(match.Headers) > 0 && !match.Headers.check("header", multiValues(request.Headers, request.HeaderValues), visit) {
+		return false
 	}
-	headerMatch := true
-	if match.Headers != nil {
-		headerMatch = match.Headers.match(request.Headers)
+	if len(match.QueryParameters) > 0 && !match.QueryParameters.check("query parameter", multiValues(request.QueryParameters, request.QueryValues), visit) {
+		return false
 	}
-	queryMatch := true
-	if match.QueryParameters != nil {
-		queryMatch = match.QueryParameters.match(request.QueryParameters)
+	if len(match.Cookies) > 0 && !match.Cookies.check("cookie", multiValues(request.Cookies, nil), visit) {
+		return false
 	}
-	bodyMatch := true
-	if match.Body != nil {
-		bodyMatch = match.Body.test(string(request.Body))
+	if len(match.PathParameters) > 0 && !match.PathParameters.check("path parameter", multiValues(match.URL.pathParameters(request.URL), nil), visit) {
+		return false
 	}
-	return urlMatch && methodMatch && headerMatch && queryMatch && bodyMatch
+	if match.Body != nil && !checkSimplex("body", match.Body, string(request.Body), visit) {
+		return false
+	}
+	return match.BodyJSONPath.check(request.Body, visit) && match.BodyXPath.check(request.Body, visit)
 }
 
-func (conditions complexConditions) match(params map[string]string) bool {
+func matchedOnly(_ string, matched bool, _ func() string) bool {
+	return matched
+}
+
+func collect(results *[]fieldMatch) fieldVisitor {
+	return func(field string, matched bool, reason func() string) bool {
+		result := fieldMatch{Field: field, Matched: matched}
+		if !matched {
+			result.Reason = reason()
+		}
+		*results = append(*results, result)
+		return true
+	}
+}
+
+func checkSimplex(field string, condition *simplexCondition, value string, visit fieldVisitor) bool {
+	reason := func() string { return fmt.Sprintf("%s %q does not match %s", field, value, condition.describe()) }
+	return visit(field, condition.test(value), reason)
+}
+
+func multiValues(flat map[string]string, multi map[string][]string) map[string][]string {
+	if multi != nil {
+		return multi
+	}
+	values := make(map[string][]string, len(flat))
+	for key, value := range flat {
+		values[key] = []string{value}
+	}
+	return values
+}
+
+func (conditions complexConditions) check(kind string, params map[string][]string, visit fieldVisitor) bool {
 	for _, condition := range conditions {
-		if !condition.test(params) {
+		field := kind + " " + condition.field
+		reason := func() string {
+			values, exists := params[condition.field]
+			switch {
+			case condition.operator == absent:
+				return fmt.Sprintf("%s is present", field)
+			case !exists:
+				return fmt.Sprintf("%s is missing", field)
+			default:
+				return fmt.Sprintf("%s %q does not match %s", field, strings.Join(values, ","), condition.describe())
+			}
+		}
+		if !visit(field, condition.test(params), reason) {
 			return false
 		}
 	}
 	return true
 }
 
+func (conditions jsonPathConditions) check(body []byte, visit fieldVisitor) bool {
+	if len(conditions) < 1 {
+		return true
+	}
+	document, valid := parseJSON(body)
+	for _, condition := range conditions {
+		field := "body json path " + condition.field
+		reason := func() string {
+			switch {
+			case !valid:
+				return "body is not a valid json"
+			case len(condition.path.evaluate(document)) < 1:
+				return fmt.Sprintf("%s is missing", field)
+			default:
+				return fmt.Sprintf("%s does not match %s", field, condition.describe())
+			}
+		}
+		if !visit(field, valid && condition.test(document), reason) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c jsonPathCondition) test(document any) bool {
+	values := c.path.evaluate(document)
+	if len(values) < 1 {
+		return c.testValues(nil, false)
+	}
+	for _, value := range values {
+		if c.testValues([]string{jsonValueAsString(value)}, true) {
+			return true
+		}
+	}
+	return false
+}
+
+func (conditions xPathConditions) check(body []byte, visit fieldVisitor) bool {
+	if len(conditions) < 1 {
+		return true
+	}
+	document, valid := parseXML(body)
+	for _, condition := range conditions {
+		field := "body xpath " + condition.field
+		reason := func() string {
+			switch {
+			case !valid:
+				return "body is not a valid xml"
+			case len(condition.path.evaluate(document)) < 1:
+				return fmt.Sprintf("%s is missing", field)
+			default:
+				return fmt.Sprintf("%s does not match %s", field, condition.describe())
+			}
+		}
+		if !visit(field, valid && condition.test(document), reason) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c xPathCondition) test(document *xmlNode) bool {
+	values := c.path.evaluate(document)
+	if len(values) < 1 {
+		return c.testValues(nil, false)
+	}
+	for _, value := range values {
+		if c.testValues([]string{value}, true) {
+			return true
+		}
+	}
+	return false
+}
+
 func (c simplexCondition) test(value string) bool {
+	return c.testValues([]string{value}, true)
+}
+
+func (c simplexCondition) testValues(values []string, found bool) bool {
+	switch c.operator {
+	case and:
+		for _, condition := range c.conditions {
+			if !condition.testValues(values, found) {
+				return false
+			}
+		}
+		return true
+	case or:
+		for _, condition := range c.conditions {
+			if condition.testValues(values, found) {
+				return true
+			}
+		}
+		return false
+	case not:
+		return !c.conditions[0].testValues(values, found)
+	case absent:
+		return !found
+	case present:
+		return found
+	}
+	if !found {
+		return false
+	}
+	switch c.operator {
+	case hasItem:
+		return slices.Contains(values, c.value)
+	case includesAll:
+		for _, item := range c.values {
+			if !slices.Contains(values, item) {
+				return false
+			}
+		}
+		return true
+	case exactList:
+		return slices.Equal(values, c.values)
+	}
+	value := strings.Join(values, ",")
+	switch c.operator {
+	case pattern:
+		return c.regex != nil && c.regex.MatchString(value)
+	case urlTemplateMatch:
+		if c.template == nil {
+			return false
+		}
+		_, matched := c.template.extract(value)
+		return matched
+	case equalToJSON:
+		return c.jsonOptions.matches(c.expectedJSON, value)
+	case equalToXML:
+		return c.expectedXML.matches(value)
+	}
 	predicate := c.operator.getPredicate()
 	if predicate == nil {
 		return false
@@ -111,25 +399,64 @@ func (c simplexCondition) test(value string) bool {
 	return predicate(c.value, value)
 }
 
-func (c complexCondition) test(value map[string]string) bool {
-	if v, exists := value[c.field]; exists {
-		predicate := c.operator.getPredicate()
-		if predicate == nil {
-			return false
-		}
-		return predicate(c.value, v)
+func (c complexCondition) test(params map[string][]string) bool {
+	values, exists := params[c.field]
+	return c.testValues(values, exists)
+}
+
+func (o operator) String() string {
+	switch o {
+	case equal:
+		return operatorEqual
+	case contains:
+		return operatorContains
+	case pattern:
+		return operatorPattern
+	case exists:
+		return operatorExists
+	case equalToJSON:
+		return operatorEqualToJSON
+	case equalToXML:
+		return operatorEqualToXML
+	case and:
+		return operatorAnd
+	case or:
+		return operatorOr
+	case not:
+		return operatorNot
+	case absent:
+		return operatorAbsent
+	case present:
+		return operatorPresent
+	case equalIgnoreCase:
+		return operatorEqualIgnoreCase
+	case containsIgnoreCase:
+		return operatorContainsIgnoreCase
+	case hasItem:
+		return operatorHasItem
+	case includesAll:
+		return operatorIncludesAll
+	case exactList:
+		return operatorExactList
+	case urlTemplateMatch:
+		return operatorURLTemplate
+	default:
+		return "undefined"
 	}
-	return false
 }
 
 func (o operator) getPredicate() biPredicate[string, string] {
 	switch o {
 	case contains:
 		return containsPredicate
-	case pattern:
-		return regexPredicate
 	case equal:
 		return equalsPredicate
+	case exists:
+		return existsPredicate
+	case equalIgnoreCase:
+		return equalsIgnoreCasePredicate
+	case containsIgnoreCase:
+		return containsIgnoreCasePredicate
 	default:
 		return nil
 	}
@@ -1,6 +1,8 @@
 package mock
 
 import (
+	"regexp"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -71,6 +73,7 @@ func TestUrlPatternMatch(t *testing.T) {
 		URL: &simplexCondition{
 			operator: pattern,
 			value:    "^/test.*",
+			regex:    regexp.MustCompile("^/test.*"),
 		},
 	}
 	req := httpRequest{
@@ -85,6 +88,7 @@ func TestUrlPatternNotMatch(t *testing.T) {
 		URL: &simplexCondition{
 			operator: pattern,
 			value:    "^/test.*",
+			regex:    regexp.MustCompile("^/test.*"),
 		},
 	}
 	req := httpRequest{
@@ -189,6 +193,7 @@ func TestHeaderEquals(t *testing.T) {
 				simplexCondition: simplexCondition{
 					operator: pattern,
 					value:    "^Chrome.*",
+					regex:    regexp.MustCompile("^Chrome.*"),
 				},
 				field: "User-Agent",
 			},
@@ -258,6 +263,7 @@ func TestHeaderPatternNotMatch(t *testing.T) {
 				simplexCondition: simplexCondition{
 					operator: pattern,
 					value:    "^Chrome.*",
+					regex:    regexp.MustCompile("^Chrome.*"),
 				},
 				field: "User-Agent",
 			},
@@ -340,6 +346,7 @@ func TestQueryParamsEquals(t *testing.T) {
 				simplexCondition: simplexCondition{
 					operator: pattern,
 					value:    "^solid*",
+					regex:    regexp.MustCompile("^solid*"),
 				},
 				field: "query",
 			},
@@ -409,6 +416,7 @@ func TestQueryParamsPatternNotMatch(t *testing.T) {
 				simplexCondition: simplexCondition{
 					operator: pattern,
 					value:    "^solid*",
+					regex:    regexp.MustCompile("^solid*"),
 				},
 				field: "query",
 			},
@@ -445,6 +453,7 @@ func TestHeaderAndQueryEqualsAndUrlNot(t *testing.T) {
 				simplexCondition: simplexCondition{
 					operator: pattern,
 					value:    "^Chrome.*",
+					regex:    regexp.MustCompile("^Chrome.*"),
 				},
 				field: "User-Agent",
 			},
@@ -524,6 +533,7 @@ func TestBodyPattern(t *testing.T) {
 		Body: &simplexCondition{
 			operator: pattern,
 			value:    `^{"[A-Za-z0-9]*":"[A-Za-z0-9]*"}`,
+			regex:    regexp.MustCompile(`^{"[A-Za-z0-9]*":"[A-Za-z0-9]*"}`),
 		},
 	}
 	req := httpRequest{
@@ -538,6 +548,7 @@ func TestBodyPatternNotMatch(t *testing.T) {
 		Body: &simplexCondition{
 			operator: pattern,
 			value:    `^{"[A-Za-z0-9]*":"[A-Za-z0-9]*"}`,
+			regex:    regexp.MustCompile(`^{"[A-Za-z0-9]*":"[A-Za-z0-9]*"}`),
 		},
 	}
 	req := httpRequest{
@@ -546,3 +557,172 @@ func TestBodyPatternNotMatch(t *testing.T) {
 	expected := reqMatch.IsExpected(req)
 	assert.False(t, expected)
 }
+
+func TestEvaluateReportsEveryField(t *testing.T) {
+	method := getMethod
+	reqMatch := requestMatch{
+		URL:    &simplexCondition{operator: pattern, value: "^/users/.*", regex: regexp.MustCompile("^/users/.*")},
+		Method: &method,
+		Headers: complexConditions{
+			{simplexCondition: simplexCondition{operator: contains, value: "json"}, field: "Accept"},
+			{simplexCondition: simplexCondition{operator: equal, value: "1"}, field: "X-Version"},
+		},
+		QueryParameters: complexConditions{
+			{simplexCondition: simplexCondition{operator: equal, value: "2"}, field: "page"},
+		},
+		Body: &simplexCondition{operator: contains, value: "name"},
+	}
+	req := httpRequest{
+		URL:             "/users/1",
+		Method:          postMethod,
+		Headers:         map[string]string{"Accept": "text/xml"},
+		QueryParameters: map[string]string{"page": "2"},
+		Body:            []byte(`{"id":1}`),
+	}
+	results := reqMatch.evaluate(req)
+	assert.Equal(t, []fieldMatch{
+		{Field: "url", Matched: true},
+		{Field: "method", Reason: "method POST is not GET"},
+		{Field: "header Accept", Reason: `header Accept "text/xml" does not match contains "json"`},
+		{Field: "header X-Version", Reason: "header X-Version is missing"},
+		{Field: "query parameter page", Matched: true},
+		{Field: "body", Reason: `body "{\"id\":1}" does not match contains "name"`},
+	}, results)
+	assert.False(t, reqMatch.IsExpected(req))
+}
+
+func TestIsExpectedAgreesWithEvaluate(t *testing.T) {
+	method := postMethod
+	path, _ := compileJSONPath("$.status")
+	reqMatch := requestMatch{
+		URL:    &simplexCondition{operator: equal, value: "/orders"},
+		Method: &method,
+		Headers: complexConditions{
+			{simplexCondition: simplexCondition{operator: absent}, field: "Authorization"},
+		},
+		BodyJSONPath: jsonPathConditions{
+			{complexCondition: complexCondition{simplexCondition: simplexCondition{operator: equal, value: "PENDING"}, field: "$.status"}, path: path},
+		},
+	}
+	requests := []httpRequest{
+		{URL: "/orders", Method: postMethod, Body: []byte(`{"status":"PENDING"}`)},
+		{URL: "/orders", Method: postMethod, Body: []byte(`{"status":"DONE"}`)},
+		{URL: "/orders", Method: postMethod, Body: []byte(`{"status":`)},
+		{URL: "/orders", Method: postMethod, Headers: map[string]string{"Authorization": "x"}, Body: []byte(`{"status":"PENDING"}`)},
+		{URL: "/users", Method: getMethod},
+	}
+	for _, req := range requests {
+		matched := true
+		for _, result := range reqMatch.evaluate(req) {
+			matched = matched && result.Matched
+		}
+		assert.Equal(t, matched, reqMatch.IsExpected(req), req.URL+" "+string(req.Body))
+	}
+}
+
+func TestResponseSequenceRepeatLast(t *testing.T) {
+	aggregate := mock{
+		Sequence: newResponseSequence([]httpResponse{{Status: 500}, {Status: 503}, {Status: 200}}, false),
+	}
+	var statuses []int
+	for i := 0; i < 5; i++ {
+		statuses = append(statuses, aggregate.nextResponse().Status)
+	}
+	assert.Equal(t, []int{500, 503, 200, 200, 200}, statuses)
+}
+
+func TestResponseSequenceLoop(t *testing.T) {
+	aggregate := mock{
+		Sequence: newResponseSequence([]httpResponse{{Status: 500}, {Status: 200}}, true),
+	}
+	var statuses []int
+	for i := 0; i < 5; i++ {
+		statuses = append(statuses, aggregate.nextResponse().Status)
+	}
+	assert.Equal(t, []int{500, 200, 500, 200, 500}, statuses)
+}
+
+func TestResponseSequenceSharedBetweenCopies(t *testing.T) {
+	aggregate := mock{
+		Sequence: newResponseSequence([]httpResponse{{Status: 500}, {Status: 200}}, false),
+	}
+	copied := aggregate
+	assert.Equal(t, 500, aggregate.nextResponse().Status)
+	assert.Equal(t, 200, copied.nextResponse().Status)
+}
+
+func TestResponseSequenceConcurrent(t *testing.T) {
+	aggregate := mock{
+		Sequence: newResponseSequence([]httpResponse{{Status: 500}, {Status: 200}}, false),
+	}
+	statuses := make(chan int, 100)
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			statuses <- aggregate.nextResponse().Status
+		}()
+	}
+	wg.Wait()
+	close(statuses)
+	failures := 0
+	for status := range statuses {
+		if status == 500 {
+			failures++
+		}
+	}
+	assert.Equal(t, 1, failures)
+}
+
+func TestNextResponseWithoutSequence(t *testing.T) {
+	aggregate := mock{Response: httpResponse{Status: 201}}
+	assert.Equal(t, 201, aggregate.nextResponse().Status)
+}
+
+func TestComplexConditionAbsentAndPresent(t *testing.T) {
+	reqMatch := requestMatch{
+		Headers: complexConditions{
+			{simplexCondition: simplexCondition{operator: absent}, field: "Authorization"},
+			{simplexCondition: simplexCondition{operator: present}, field: "X-Request-Id"},
+		},
+		Cookies: complexConditions{
+			{simplexCondition: simplexCondition{operator: absent}, field: "session"},
+		},
+	}
+	assert.True(t, reqMatch.IsExpected(httpRequest{Headers: map[string]string{"X-Request-Id": ""}}))
+	assert.False(t, reqMatch.IsExpected(httpRequest{Headers: map[string]string{"X-Request-Id": "1", "Authorization": "Bearer x"}}))
+	assert.False(t, reqMatch.IsExpected(httpRequest{Headers: map[string]string{}}))
+	assert.False(t, reqMatch.IsExpected(httpRequest{
+		Headers: map[string]string{"X-Request-Id": "1"},
+		Cookies: map[string]string{"session": "abc"},
+	}))
+	results := reqMatch.evaluate(httpRequest{
+		Headers: map[string]string{"Authorization": "Bearer x"},
+		Cookies: map[string]string{"session": "abc"},
+	})
+	assert.Equal(t, []fieldMatch{
+		{Field: "header Authorization", Reason: "header Authorization is present"},
+		{Field: "header X-Request-Id", Reason: "header X-Request-Id is missing"},
+		{Field: "cookie session", Reason: "cookie session is present"},
+	}, results)
+}
+
+func TestAbsentInsideCombinators(t *testing.T) {
+	condition := complexCondition{
+		simplexCondition: simplexCondition{operator: or, conditions: []*simplexCondition{
+			{operator: absent},
+			{operator: equal, value: "v2"},
+		}},
+		field: "X-Version",
+	}
+	assert.True(t, condition.test(map[string][]string{}))
+	assert.True(t, condition.test(map[string][]string{"X-Version": {"v2"}}))
+	assert.False(t, condition.test(map[string][]string{"X-Version": {"v1"}}))
+	negated := complexCondition{
+		simplexCondition: simplexCondition{operator: not, conditions: []*simplexCondition{{operator: present}}},
+		field:            "X-Version",
+	}
+	assert.True(t, negated.test(map[string][]string{}))
+	assert.False(t, negated.test(map[string][]string{"X-Version": {"v1"}}))
+}
@@ -1,18 +1,26 @@
 package mock
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 type Error struct {
-	Err         error  `json:"error"`
-	Cause       string `json:"cause"`
-	Code        string `json:"code"`
-	Description string `json:"description"`
+	Err         error      `json:"error"`
+	Cause       string     `json:"cause"`
+	Code        string     `json:"code"`
+	Description string     `json:"description"`
+	NearMisses  []nearMiss `json:"near_misses,omitempty"`
 }
 
 const (
-	errorTemplate      = "[Err: %v, Cause: %v, Code: %v, Description: %v]"
-	invalidRequestCode = "invalid_request"
-	mockNotFoundCode   = "mock_not_found"
+	errorTemplate        = "[Err: %v, Cause: %v, Code: %v, Description: %v]"
+	invalidRequestCode   = "invalid_request"
+	mockNotFoundCode     = "mock_not_found"
+	mappingNotFoundCode  = "mapping_not_found"
+	scenarioNotFoundCode = "scenario_not_found"
+	templateErrorCode    = "template_error"
+	callbackErrorCode    = "callback_error"
 )
 
 func (err Error) Error() string {
@@ -35,3 +43,55 @@ func mockNotFound(request httpRequest) error {
 		Cause:       description,
 	}
 }
+
+func mappingNotFound(id string) error {
+	description := fmt.Sprintf("mapping with id %s not found.", id)
+	return Error{
+		Code:        mappingNotFoundCode,
+		Description: description,
+		Cause:       description,
+	}
+}
+
+func scenarioNotFound(name string) error {
+	description := fmt.Sprintf("scenario %s not found.", name)
+	return Error{
+		Code:        scenarioNotFoundCode,
+		Description: description,
+		Cause:       description,
+	}
+}
+
+func templateError(err error) error {
+	description := fmt.Sprintf("error rendering the response template: %v", err)
+	return Error{
+		Err:         err,
+		Code:        templateErrorCode,
+		Description: description,
+		Cause:       description,
+	}
+}
+
+func callbackError(err error) error {
+	description := fmt.Sprintf("error executing the callback responder: %v", err)
+	return Error{
+		Err:         err,
+		Code:        callbackErrorCode,
+		Description: description,
+		Cause:       description,
+	}
+}
+
+func mockNotFoundWithNearMisses(request httpRequest, nearMisses []nearMiss) error {
+	err := mockNotFound(request).(Error)
+	if len(nearMisses) < 1 {
+		return err
+	}
+	var closest []string
+	for _, nearMiss := range nearMisses {
+		closest = append(closest, nearMiss.String())
+	}
+	err.Description = fmt.Sprintf("%s closest mappings: %s.", err.Description, strings.Join(closest, "; "))
+	err.NearMisses = nearMisses
+	return err
+}
@@ -1,6 +1,7 @@
 package mock
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -13,5 +14,38 @@ func TestInvalidRequest(t *testing.T) {
 
 func TestMockNotFound(t *testing.T) {
 	err := mockNotFound(httpRequest{})
-	assert.Equal(t, "[Err: <nil>, Cause: mapping not found for request {  map[] map[] []}., Code: mock_not_found, Description: mapping not found for request {  map[] map[] []}.]", err.Error())
+	assert.Equal(t, "[Err: <nil>, Cause: mapping not found for request {  map[] map[] map[] [] map[] map[] map[]}., Code: mock_not_found, Description: mapping not found for request {  map[] map[] map[] [] map[] map[] map[]}.]", err.Error())
+}
+
+func TestMockNotFoundWithNearMisses(t *testing.T) {
+	nearMisses := []nearMiss{
+		{
+			MappingID: "1",
+			Matched:   1,
+			Total:     2,
+			Fields: []fieldMatch{
+				{Field: "url", Matched: true},
+				{Field: "method", Reason: "method POST is not GET"},
+			},
+		},
+	}
+	err := mockNotFoundWithNearMisses(httpRequest{}, nearMisses).(Error)
+	assert.Equal(t, "mock_not_found", err.Code)
+	assert.Equal(t, nearMisses, err.NearMisses)
+	assert.Equal(t, "mapping not found for request {  map[] map[] map[] [] map[] map[] map[]}. closest mappings: mapping 1 (1/2): url matched, method POST is not GET.", err.Description)
+}
+
+func TestMockNotFoundWithoutNearMisses(t *testing.T) {
+	err := mockNotFoundWithNearMisses(httpRequest{}, nil)
+	assert.Equal(t, mockNotFound(httpRequest{}).Error(), err.Error())
+}
+
+func TestMappingNotFound(t *testing.T) {
+	err := mappingNotFound("123")
+	assert.Equal(t, "[Err: <nil>, Cause: mapping with id 123 not found., Code: mapping_not_found, Description: mapping with id 123 not found.]", err.Error())
+}
+
+func TestTemplateError(t *testing.T) {
+	err := templateError(errors.New("any error"))
+	assert.Equal(t, "[Err: any error, Cause: error rendering the response template: any error, Code: template_error, Description: error rendering the response template: any error]", err.Error())
 }
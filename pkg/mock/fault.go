@@ -0,0 +1,73 @@
+package mock
+
+import (
+	"crypto/rand"
+	"fmt"
+	"net"
+	"net/http"
+)
+
+type Fault string
+
+const (
+	FaultConnectionResetByPeer Fault = "connection_reset_by_peer"
+	FaultEmptyResponse         Fault = "empty_response"
+	FaultRandomDataThenClose   Fault = "random_data_then_close"
+	FaultTruncatedResponse     Fault = "truncated_response"
+)
+
+const randomDataSize = 1024
+
+func toFault(name string) (Fault, error) {
+	fault := Fault(name)
+	switch fault {
+	case "", FaultConnectionResetByPeer, FaultEmptyResponse, FaultRandomDataThenClose, FaultTruncatedResponse:
+		return fault, nil
+	default:
+		return "", invalidRequest(fmt.Sprintf("the fault %s is not supported.", name))
+	}
+}
+
+func writeFault(writer http.ResponseWriter, response *httpResponse) {
+	hijacker, ok := writer.(http.Hijacker)
+	if !ok {
+		LogError("the response writer does not support hijacking, fault %s could not be injected", response.Fault)
+		writer.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	conn, _, err := hijacker.Hijack()
+	if err != nil {
+		LogError("error hijacking the connection, error: %v", err)
+		return
+	}
+	defer conn.Close()
+	switch response.Fault {
+	case FaultConnectionResetByPeer:
+		if tcpConn, isTCP := conn.(*net.TCPConn); isTCP {
+			_ = tcpConn.SetLinger(0)
+		}
+	case FaultRandomDataThenClose:
+		data := make([]byte, randomDataSize)
+		rand.Read(data)
+		_, err = conn.Write(data)
+	case FaultTruncatedResponse:
+		_, err = conn.Write(truncatedResponse(response))
+	}
+	if err != nil {
+		LogError(fmt.Sprintf("error writing fault %s, error: %v", response.Fault, err))
+	}
+}
+
+func truncatedResponse(response *httpResponse) []byte {
+	status := response.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	body := response.Body
+	data := fmt.Sprintf("HTTP/1.1 %d %s\r\n", status, http.StatusText(status))
+	for key, value := range response.Headers {
+		data += fmt.Sprintf("%s: %s\r\n", key, value)
+	}
+	data += fmt.Sprintf("Content-Length: %d\r\n\r\n", len(body)+randomDataSize)
+	return append([]byte(data), body[:len(body)/2]...)
+}
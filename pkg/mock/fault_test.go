@@ -0,0 +1,80 @@
+package mock
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	mocking "github.com/stretchr/testify/mock"
+)
+
+func startFaultRouter(t *testing.T, fault Fault) string {
+	srv := serviceMock{}
+	srv.On("Match", mocking.AnythingOfType("mock.httpRequest")).Return(&httpResponse{
+		Status: http.StatusOK,
+		Body:   []byte(`{"name":"any-name"}`),
+		Fault:  fault,
+	}, nil)
+	router := newRouter(&srv)
+	baseURL, err := router.StartEphemeral()
+	assert.Nil(t, err)
+	t.Cleanup(func() { router.Stop(context.Background()) })
+	return baseURL
+}
+
+func TestFaultConnectionResetByPeer(t *testing.T) {
+	baseURL := startFaultRouter(t, FaultConnectionResetByPeer)
+	_, err := http.Get(baseURL + "/fault")
+	assert.Error(t, err)
+}
+
+func TestFaultEmptyResponse(t *testing.T) {
+	baseURL := startFaultRouter(t, FaultEmptyResponse)
+	_, err := http.Get(baseURL + "/fault")
+	assert.Error(t, err)
+}
+
+func TestFaultRandomDataThenClose(t *testing.T) {
+	baseURL := startFaultRouter(t, FaultRandomDataThenClose)
+	_, err := http.Get(baseURL + "/fault")
+	assert.Error(t, err)
+}
+
+func TestFaultTruncatedResponse(t *testing.T) {
+	baseURL := startFaultRouter(t, FaultTruncatedResponse)
+	response, err := http.Get(baseURL + "/fault")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	_, err = io.ReadAll(response.Body)
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+}
+
+func TestFaultWithoutHijackSupport(t *testing.T) {
+	response := httptest.NewRecorder()
+	writeFault(response, &httpResponse{Fault: FaultEmptyResponse})
+	assert.Equal(t, http.StatusInternalServerError, response.Code)
+}
+
+func TestToFault(t *testing.T) {
+	fault, err := toFault("connection_reset_by_peer")
+	assert.Nil(t, err)
+	assert.Equal(t, FaultConnectionResetByPeer, fault)
+	fault, err = toFault("")
+	assert.Nil(t, err)
+	assert.Empty(t, fault)
+	_, err = toFault("timeout")
+	assert.Error(t, err)
+	assert.Equal(t, "the fault timeout is not supported.", err.(Error).Cause)
+}
+
+func TestTruncatedResponse(t *testing.T) {
+	data := truncatedResponse(&httpResponse{
+		Status:  http.StatusAccepted,
+		Body:    []byte("0123456789"),
+		Headers: map[string]string{"Content-Type": "text/plain"},
+	})
+	assert.Equal(t, "HTTP/1.1 202 Accepted\r\nContent-Type: text/plain\r\nContent-Length: 1034\r\n\r\n01234", string(data))
+}
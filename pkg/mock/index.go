@@ -0,0 +1,233 @@
+package mock
+
+import (
+	"regexp/syntax"
+	"strings"
+)
+
+const anyMethod = ""
+
+type urlKeyKind uint8
+
+const (
+	exactURL urlKeyKind = iota
+	prefixURL
+)
+
+type urlKey struct {
+	kind  urlKeyKind
+	value string
+}
+
+type mappingIndex struct {
+	methods  map[string]*urlIndex
+	entries  map[string]indexEntry
+	fallback map[string]struct{}
+}
+
+type indexEntry struct {
+	method string
+	keys   []urlKey
+}
+
+type urlIndex struct {
+	exact    map[string]map[string]struct{}
+	prefixes *prefixNode
+}
+
+type prefixNode struct {
+	children map[byte]*prefixNode
+	ids      map[string]struct{}
+}
+
+func newMappingIndex() *mappingIndex {
+	return &mappingIndex{
+		methods:  map[string]*urlIndex{},
+		entries:  map[string]indexEntry{},
+		fallback: map[string]struct{}{},
+	}
+}
+
+func (index *mappingIndex) add(aggregate mock) {
+	index.remove(aggregate.ID)
+	keys := aggregate.Request.URL.urlKeys()
+	method := anyMethod
+	if aggregate.Request.Method != nil {
+		method = *aggregate.Request.Method
+	}
+	if len(keys) < 1 {
+		index.fallback[aggregate.ID] = struct{}{}
+		index.entries[aggregate.ID] = indexEntry{}
+		return
+	}
+	urls, exists := index.methods[method]
+	if !exists {
+		urls = &urlIndex{exact: map[string]map[string]struct{}{}, prefixes: &prefixNode{}}
+		index.methods[method] = urls
+	}
+	for _, key := range keys {
+		urls.add(key, aggregate.ID)
+	}
+	index.entries[aggregate.ID] = indexEntry{method: method, keys: keys}
+}
+
+func (index *mappingIndex) remove(id string) {
+	entry, exists := index.entries[id]
+	if !exists {
+		return
+	}
+	delete(index.entries, id)
+	delete(index.fallback, id)
+	if urls, exists := index.methods[entry.method]; exists {
+		for _, key := range entry.keys {
+			urls.remove(key, id)
+		}
+	}
+}
+
+func (index *mappingIndex) clear() {
+	index.methods = map[string]*urlIndex{}
+	index.entries = map[string]indexEntry{}
+	index.fallback = map[string]struct{}{}
+}
+
+func (index *mappingIndex) candidates(request httpRequest) map[string]struct{} {
+	ids := map[string]struct{}{}
+	for id := range index.fallback {
+		ids[id] = struct{}{}
+	}
+	for _, method := range []string{anyMethod, request.Method} {
+		if urls, exists := index.methods[method]; exists {
+			urls.collect(request.URL, ids)
+		}
+		if method == request.Method {
+			break
+		}
+	}
+	return ids
+}
+
+func (urls *urlIndex) add(key urlKey, id string) {
+	if key.kind == exactURL {
+		if urls.exact[key.value] == nil {
+			urls.exact[key.value] = map[string]struct{}{}
+		}
+		urls.exact[key.value][id] = struct{}{}
+		return
+	}
+	node := urls.prefixes
+	for i := 0; i < len(key.value); i++ {
+		if node.children == nil {
+			node.children = map[byte]*prefixNode{}
+		}
+		child, exists := node.children[key.value[i]]
+		if !exists {
+			child = &prefixNode{}
+			node.children[key.value[i]] = child
+		}
+		node = child
+	}
+	if node.ids == nil {
+		node.ids = map[string]struct{}{}
+	}
+	node.ids[id] = struct{}{}
+}
+
+func (urls *urlIndex) remove(key urlKey, id string) {
+	if key.kind == exactURL {
+		delete(urls.exact[key.value], id)
+		if len(urls.exact[key.value]) < 1 {
+			delete(urls.exact, key.value)
+		}
+		return
+	}
+	node := urls.prefixes
+	for i := 0; i < len(key.value) && node != nil; i++ {
+		node = node.children[key.value[i]]
+	}
+	if node != nil {
+		delete(node.ids, id)
+	}
+}
+
+func (urls *urlIndex) collect(url string, ids map[string]struct{}) {
+	for id := range urls.exact[url] {
+		ids[id] = struct{}{}
+	}
+	node := urls.prefixes
+	for i := 0; node != nil; i++ {
+		for id := range node.ids {
+			ids[id] = struct{}{}
+		}
+		if i >= len(url) {
+			break
+		}
+		node = node.children[url[i]]
+	}
+}
+
+func (c *simplexCondition) urlKeys() []urlKey {
+	if c == nil {
+		return nil
+	}
+	switch c.operator {
+	case equal:
+		return []urlKey{{kind: exactURL, value: c.value}}
+	case pattern:
+		if prefix, ok := anchoredLiteralPrefix(c.value); ok {
+			return []urlKey{{kind: prefixURL, value: prefix}}
+		}
+	case urlTemplateMatch:
+		if c.template != nil {
+			return []urlKey{{kind: prefixURL, value: c.template.literalPrefix()}}
+		}
+	case and:
+		for _, condition := range c.conditions {
+			if keys := condition.urlKeys(); len(keys) > 0 {
+				return keys
+			}
+		}
+	case or:
+		var keys []urlKey
+		for _, condition := range c.conditions {
+			conditionKeys := condition.urlKeys()
+			if len(conditionKeys) < 1 {
+				return nil
+			}
+			keys = append(keys, conditionKeys...)
+		}
+		return keys
+	}
+	return nil
+}
+
+func anchoredLiteralPrefix(pattern string) (string, bool) {
+	expression, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return "", false
+	}
+	expression = expression.Simplify()
+	if expression.Op != syntax.OpConcat || len(expression.Sub) < 2 {
+		return "", false
+	}
+	begin, literal := expression.Sub[0], expression.Sub[1]
+	if begin.Op != syntax.OpBeginText || literal.Op != syntax.OpLiteral || literal.Flags&syntax.FoldCase != 0 {
+		return "", false
+	}
+	return string(literal.Rune), true
+}
+
+func (template urlTemplate) literalPrefix() string {
+	var prefix strings.Builder
+	prefix.WriteString("/")
+	for i, segment := range template {
+		if segment.parameter != "" {
+			break
+		}
+		prefix.WriteString(segment.literal)
+		if i < len(template)-1 {
+			prefix.WriteString("/")
+		}
+	}
+	return prefix.String()
+}
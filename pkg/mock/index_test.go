@@ -0,0 +1,180 @@
+package mock
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func indexedMock(id string, method *string, url *simplexCondition) mock {
+	return mock{ID: id, Request: requestMatch{Method: method, URL: url}}
+}
+
+func candidateIDs(index *mappingIndex, request httpRequest) []string {
+	var ids []string
+	for id := range index.candidates(request) {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func TestIndexExactURL(t *testing.T) {
+	index := newMappingIndex()
+	index.add(indexedMock("1", nil, &simplexCondition{operator: equal, value: "/users"}))
+	index.add(indexedMock("2", nil, &simplexCondition{operator: equal, value: "/orders"}))
+	assert.Equal(t, []string{"1"}, candidateIDs(index, httpRequest{URL: "/users", Method: getMethod}))
+	assert.Empty(t, candidateIDs(index, httpRequest{URL: "/users/1", Method: getMethod}))
+}
+
+func TestIndexPatternPrefix(t *testing.T) {
+	index := newMappingIndex()
+	index.add(indexedMock("1", nil, &simplexCondition{operator: pattern, value: "^/users/[0-9]+$"}))
+	index.add(indexedMock("2", nil, &simplexCondition{operator: pattern, value: "^/orders/.*"}))
+	assert.Equal(t, []string{"1"}, candidateIDs(index, httpRequest{URL: "/users/1", Method: getMethod}))
+	assert.Empty(t, candidateIDs(index, httpRequest{URL: "/user", Method: getMethod}))
+}
+
+func TestIndexURLTemplatePrefix(t *testing.T) {
+	orders, _ := compileCondition(urlTemplateMatch, "/users/{id}/orders")
+	users, _ := compileCondition(urlTemplateMatch, "/{tenant}/users")
+	index := newMappingIndex()
+	index.add(indexedMock("1", nil, orders))
+	index.add(indexedMock("2", nil, users))
+	assert.Equal(t, []string{"1", "2"}, candidateIDs(index, httpRequest{URL: "/users/1/orders", Method: getMethod}))
+	assert.Equal(t, []string{"2"}, candidateIDs(index, httpRequest{URL: "/acme/users", Method: getMethod}))
+}
+
+func TestIndexMethodBuckets(t *testing.T) {
+	get := getMethod
+	post := postMethod
+	index := newMappingIndex()
+	index.add(indexedMock("1", &get, &simplexCondition{operator: equal, value: "/users"}))
+	index.add(indexedMock("2", &post, &simplexCondition{operator: equal, value: "/users"}))
+	index.add(indexedMock("3", nil, &simplexCondition{operator: equal, value: "/users"}))
+	assert.Equal(t, []string{"1", "3"}, candidateIDs(index, httpRequest{URL: "/users", Method: getMethod}))
+	assert.Equal(t, []string{"2", "3"}, candidateIDs(index, httpRequest{URL: "/users", Method: postMethod}))
+}
+
+func TestIndexFallback(t *testing.T) {
+	index := newMappingIndex()
+	index.add(indexedMock("1", nil, nil))
+	index.add(indexedMock("2", nil, &simplexCondition{operator: contains, value: "users"}))
+	index.add(indexedMock("3", nil, &simplexCondition{operator: pattern, value: ".*/users"}))
+	index.add(indexedMock("4", nil, &simplexCondition{operator: equal, value: "/orders"}))
+	assert.Equal(t, []string{"1", "2", "3"}, candidateIDs(index, httpRequest{URL: "/users", Method: getMethod}))
+}
+
+func TestIndexCombinedConditions(t *testing.T) {
+	index := newMappingIndex()
+	index.add(indexedMock("1", nil, &simplexCondition{operator: or, conditions: []*simplexCondition{
+		{operator: equal, value: "/users"},
+		{operator: equal, value: "/customers"},
+	}}))
+	index.add(indexedMock("2", nil, &simplexCondition{operator: and, conditions: []*simplexCondition{
+		{operator: contains, value: "users"},
+		{operator: pattern, value: "^/users"},
+	}}))
+	index.add(indexedMock("3", nil, &simplexCondition{operator: or, conditions: []*simplexCondition{
+		{operator: equal, value: "/orders"},
+		{operator: contains, value: "orders"},
+	}}))
+	assert.Equal(t, []string{"1", "2", "3"}, candidateIDs(index, httpRequest{URL: "/users", Method: getMethod}))
+	assert.Equal(t, []string{"1", "3"}, candidateIDs(index, httpRequest{URL: "/customers", Method: getMethod}))
+}
+
+func TestIndexUpdateAndRemove(t *testing.T) {
+	index := newMappingIndex()
+	index.add(indexedMock("1", nil, &simplexCondition{operator: equal, value: "/users"}))
+	index.add(indexedMock("1", nil, &simplexCondition{operator: pattern, value: "^/orders"}))
+	assert.Empty(t, candidateIDs(index, httpRequest{URL: "/users", Method: getMethod}))
+	assert.Equal(t, []string{"1"}, candidateIDs(index, httpRequest{URL: "/orders/1", Method: getMethod}))
+	index.remove("1")
+	assert.Empty(t, candidateIDs(index, httpRequest{URL: "/orders/1", Method: getMethod}))
+	index.add(indexedMock("2", nil, nil))
+	index.clear()
+	assert.Empty(t, candidateIDs(index, httpRequest{URL: "/users", Method: getMethod}))
+}
+
+func TestAnchoredLiteralPrefix(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		prefix   string
+		expected bool
+	}{
+		{"^/users/[0-9]+$", "/users/", true},
+		{"^/users$", "/users", true},
+		{`\A/users/.*`, "/users/", true},
+		{"/users/.*", "", false},
+		{"^(?i)/users", "", false},
+		{"^[a-z]+", "", false},
+		{"^/users|^/orders", "", false},
+		{"^/users/(", "", false},
+	}
+	for _, test := range tests {
+		prefix, ok := anchoredLiteralPrefix(test.pattern)
+		assert.Equal(t, test.expected, ok, test.pattern)
+		assert.Equal(t, test.prefix, prefix, test.pattern)
+	}
+}
+
+func TestFindCandidates(t *testing.T) {
+	repo := newRepository().(*inMemoryRepository)
+	repo.Save(indexedMock("1", nil, &simplexCondition{operator: equal, value: "/users"}))
+	repo.Save(indexedMock("2", nil, &simplexCondition{operator: equal, value: "/orders"}))
+	candidates := repo.FindCandidates(httpRequest{URL: "/users", Method: getMethod})
+	assert.Len(t, candidates, 1)
+	assert.Equal(t, "1", candidates[0].ID)
+	repo.Delete("1")
+	assert.Empty(t, repo.FindCandidates(httpRequest{URL: "/users", Method: getMethod}))
+	repo.DeleteAll()
+	assert.Empty(t, repo.FindCandidates(httpRequest{URL: "/orders", Method: getMethod}))
+}
+
+type scanRepository struct {
+	Repository
+}
+
+func benchmarkService(size int, indexed bool) Service {
+	repo := newRepository().(*inMemoryRepository)
+	for i := 0; i < size; i++ {
+		method := getMethod
+		url := &simplexCondition{operator: equal, value: fmt.Sprintf("/resources/%d", i)}
+		if i%10 == 0 {
+			url, _ = compileCondition(pattern, fmt.Sprintf("^/resources/%d/items/[0-9]+$", i))
+		}
+		aggregate := indexedMock(fmt.Sprint(i), &method, url)
+		repo.storage.Store(aggregate.ID, aggregate)
+		repo.index.add(aggregate)
+	}
+	if !indexed {
+		return newService(scanRepository{repo}, newJournal(), newScenarios())
+	}
+	return newService(repo, newJournal(), newScenarios())
+}
+
+func BenchmarkMatch(b *testing.B) {
+	for _, engine := range []string{"indexed", "scan"} {
+		for _, size := range []int{100, 1000, 10000} {
+			service := benchmarkService(size, engine == "indexed")
+			requests := map[string]httpRequest{
+				"hit":  {URL: fmt.Sprintf("/resources/%d", size/2+1), Method: getMethod},
+				"miss": {URL: "/unknown", Method: getMethod},
+			}
+			for _, name := range []string{"hit", "miss"} {
+				request := requests[name]
+				b.Run(fmt.Sprintf("%s/%s/%d", engine, name, size), func(b *testing.B) {
+					stdout := os.Stdout
+					os.Stdout, _ = os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+					defer func() { os.Stdout = stdout }()
+					for i := 0; i < b.N; i++ {
+						service.Match(request)
+					}
+				})
+			}
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package mock
+
+import (
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Journal interface {
+	Record(entry journalEntry)
+	GetAll() []journalEntry
+	Clear()
+}
+
+type ReceivedRequest struct {
+	Method          string            `json:"method"`
+	URL             string            `json:"url"`
+	Headers         map[string]string `json:"headers"`
+	QueryParameters map[string]string `json:"query_parameters"`
+	Body            string            `json:"body"`
+}
+
+type JournalEntry struct {
+	ID         string          `json:"id"`
+	Request    ReceivedRequest `json:"request"`
+	ReceivedAt time.Time       `json:"received_at"`
+	Matched    bool            `json:"matched"`
+	MappingID  string          `json:"mapping_id,omitempty"`
+	MatchRule  string          `json:"match_rule,omitempty"`
+}
+
+type journalEntry struct {
+	id         string
+	request    httpRequest
+	receivedAt time.Time
+	mappingID  string
+	matchRule  string
+}
+
+type inMemoryJournal struct {
+	mutex   sync.RWMutex
+	entries []journalEntry
+}
+
+func newJournal() Journal {
+	return &inMemoryJournal{}
+}
+
+func newJournalEntry(request httpRequest, mappingID string, matchRule string) journalEntry {
+	uid, _ := uuid.NewUUID()
+	return journalEntry{
+		id:         uid.String(),
+		request:    request,
+		receivedAt: time.Now(),
+		mappingID:  mappingID,
+		matchRule:  matchRule,
+	}
+}
+
+func (j *inMemoryJournal) Record(entry journalEntry) {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	j.entries = append(j.entries, entry)
+}
+
+func (j *inMemoryJournal) GetAll() []journalEntry {
+	j.mutex.RLock()
+	defer j.mutex.RUnlock()
+	results := make([]journalEntry, len(j.entries))
+	copy(results, j.entries)
+	return results
+}
+
+func (j *inMemoryJournal) Clear() {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	j.entries = nil
+}
+
+func (entry journalEntry) toPublic() JournalEntry {
+	return JournalEntry{
+		ID: entry.id,
+		Request: ReceivedRequest{
+			Method:          entry.request.Method,
+			URL:             entry.request.URL,
+			Headers:         entry.request.Headers,
+			QueryParameters: entry.request.QueryParameters,
+			Body:            string(entry.request.Body),
+		},
+		ReceivedAt: entry.receivedAt,
+		Matched:    entry.mappingID != "",
+		MappingID:  entry.mappingID,
+		MatchRule:  entry.matchRule,
+	}
+}
@@ -0,0 +1,73 @@
+package mock
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJournalRecord(t *testing.T) {
+	journal := newJournal()
+	journal.Record(newJournalEntry(httpRequest{URL: "/first"}, "1", ""))
+	journal.Record(newJournalEntry(httpRequest{URL: "/second"}, "", ""))
+	entries := journal.GetAll()
+	assert.Equal(t, 2, len(entries))
+	assert.Equal(t, "/first", entries[0].request.URL)
+	assert.Equal(t, "/second", entries[1].request.URL)
+	assert.NotEqual(t, entries[0].id, entries[1].id)
+}
+
+func TestJournalEmpty(t *testing.T) {
+	journal := newJournal()
+	assert.Empty(t, journal.GetAll())
+}
+
+func TestJournalClear(t *testing.T) {
+	journal := newJournal()
+	journal.Record(newJournalEntry(httpRequest{URL: "/first"}, "", ""))
+	journal.Clear()
+	assert.Empty(t, journal.GetAll())
+}
+
+func TestJournalConcurrentRecord(t *testing.T) {
+	journal := newJournal()
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			journal.Record(newJournalEntry(httpRequest{URL: "/concurrent"}, "", ""))
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 50, len(journal.GetAll()))
+}
+
+func TestJournalEntryToPublic(t *testing.T) {
+	entry := newJournalEntry(httpRequest{
+		URL:             "/users",
+		Method:          getMethod,
+		Headers:         map[string]string{"Accept": "application/json"},
+		QueryParameters: map[string]string{"page": "1"},
+		Body:            []byte(`{"name":"any"}`),
+	}, "mapping-1", matchRulePriority)
+	public := entry.toPublic()
+	assert.Equal(t, entry.id, public.ID)
+	assert.Equal(t, "/users", public.Request.URL)
+	assert.Equal(t, getMethod, public.Request.Method)
+	assert.Equal(t, "application/json", public.Request.Headers["Accept"])
+	assert.Equal(t, "1", public.Request.QueryParameters["page"])
+	assert.Equal(t, `{"name":"any"}`, public.Request.Body)
+	assert.True(t, public.Matched)
+	assert.Equal(t, "mapping-1", public.MappingID)
+	assert.Equal(t, matchRulePriority, public.MatchRule)
+	assert.False(t, public.ReceivedAt.IsZero())
+}
+
+func TestJournalEntryToPublicUnmatched(t *testing.T) {
+	public := newJournalEntry(httpRequest{URL: "/users"}, "", "").toPublic()
+	assert.False(t, public.Matched)
+	assert.Empty(t, public.MappingID)
+	assert.Empty(t, public.MatchRule)
+}
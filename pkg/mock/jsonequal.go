@@ -0,0 +1,94 @@
+package mock
+
+import "encoding/json"
+
+const (
+	optionIgnoreExtraFields = "ignore_extra_fields"
+	optionIgnoreArrayOrder  = "ignore_array_order"
+)
+
+type jsonEqualityOptions struct {
+	ignoreExtraFields bool
+	ignoreArrayOrder  bool
+}
+
+func (options jsonEqualityOptions) isSet() bool {
+	return options.ignoreExtraFields || options.ignoreArrayOrder
+}
+
+type jsonDocument struct {
+	value any
+}
+
+func (options jsonEqualityOptions) matches(expected *jsonDocument, actual string) bool {
+	if expected == nil {
+		return false
+	}
+	document, valid := parseJSON([]byte(actual))
+	return valid && options.equal(expected.value, document)
+}
+
+func (options jsonEqualityOptions) equal(expected any, actual any) bool {
+	switch e := expected.(type) {
+	case map[string]any:
+		a, ok := actual.(map[string]any)
+		if !ok || (!options.ignoreExtraFields && len(a) != len(e)) {
+			return false
+		}
+		for key, value := range e {
+			actualValue, exists := a[key]
+			if !exists || !options.equal(value, actualValue) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		a, ok := actual.([]any)
+		if !ok || len(a) != len(e) {
+			return false
+		}
+		if options.ignoreArrayOrder {
+			return options.matchUnordered(e, a, make([]bool, len(a)))
+		}
+		for i := range e {
+			if !options.equal(e[i], a[i]) {
+				return false
+			}
+		}
+		return true
+	case json.Number:
+		a, ok := actual.(json.Number)
+		return ok && numbersEqual(e, a)
+	default:
+		return expected == actual
+	}
+}
+
+func (options jsonEqualityOptions) matchUnordered(expected []any, actual []any, used []bool) bool {
+	if len(expected) < 1 {
+		return true
+	}
+	for i, value := range actual {
+		if used[i] || !options.equal(expected[0], value) {
+			continue
+		}
+		used[i] = true
+		if options.matchUnordered(expected[1:], actual, used) {
+			return true
+		}
+		used[i] = false
+	}
+	return false
+}
+
+func numbersEqual(expected json.Number, actual json.Number) bool {
+	if expected == actual {
+		return true
+	}
+	e, err := expected.Float64()
+	if err != nil {
+		return false
+	}
+	a, err := actual.Float64()
+	return err == nil && e == a
+}
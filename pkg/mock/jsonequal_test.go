@@ -0,0 +1,77 @@
+package mock
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func jsonEquals(expected string, actual string, options jsonEqualityOptions) bool {
+	condition, _ := compileCondition(equalToJSON, expected)
+	condition.jsonOptions = options
+	return condition.test(actual)
+}
+
+func TestJSONEquals(t *testing.T) {
+	strict := jsonEqualityOptions{}
+	assert.True(t, jsonEquals(`{"a":1,"b":2}`, `{"b":2, "a":1}`, strict))
+	assert.True(t, jsonEquals(`{"a":1.0}`, `{"a":1}`, strict))
+	assert.True(t, jsonEquals(`{"a":[1,{"b":null}]}`, `{"a":[1,{"b":null}]}`, strict))
+	assert.False(t, jsonEquals(`{"a":1}`, `{"a":"1"}`, strict))
+	assert.False(t, jsonEquals(`{"a":1}`, `{"a":1,"b":2}`, strict))
+	assert.False(t, jsonEquals(`[1,2]`, `[2,1]`, strict))
+	assert.False(t, jsonEquals(`{"a":1}`, `not json`, strict))
+	assert.False(t, jsonEquals(`{"a":1}`, `{"a":1} garbage`, strict))
+	assert.False(t, jsonEquals(`{"a":1}`, `{"a":1}{"a":1}`, strict))
+	assert.True(t, jsonEquals(`{"a":1}`, " {\"a\":1}\n", strict))
+}
+
+func TestJSONEqualsWithOptions(t *testing.T) {
+	ignoreExtra := jsonEqualityOptions{ignoreExtraFields: true}
+	assert.True(t, jsonEquals(`{"a":1}`, `{"a":1,"b":2}`, ignoreExtra))
+	assert.True(t, jsonEquals(`{"a":{"c":1}}`, `{"a":{"c":1,"d":2}}`, ignoreExtra))
+	assert.False(t, jsonEquals(`{"a":1,"b":2}`, `{"a":1}`, ignoreExtra))
+	ignoreOrder := jsonEqualityOptions{ignoreArrayOrder: true}
+	assert.True(t, jsonEquals(`[1,2,{"a":[3,4]}]`, `[{"a":[4,3]},2,1]`, ignoreOrder))
+	assert.True(t, jsonEquals(`[1,1,2]`, `[1,2,1]`, ignoreOrder))
+	assert.False(t, jsonEquals(`[1,1,2]`, `[1,2,2]`, ignoreOrder))
+	both := jsonEqualityOptions{ignoreExtraFields: true, ignoreArrayOrder: true}
+	assert.True(t, jsonEquals(`[{"id":1},{"id":2}]`, `[{"id":2,"x":1},{"id":1,"y":2}]`, both))
+}
+
+func TestBuildBodyCondition(t *testing.T) {
+	condition, err := buildBodyCondition(conditionDTO{
+		"equal_to_json":       `{"a":1}`,
+		"ignore_extra_fields": "true",
+		"ignore_array_order":  "false",
+	})
+	require.Nil(t, err)
+	assert.Equal(t, &simplexCondition{
+		operator:     equalToJSON,
+		value:        `{"a":1}`,
+		jsonOptions:  jsonEqualityOptions{ignoreExtraFields: true},
+		expectedJSON: &jsonDocument{value: map[string]any{"a": json.Number("1")}},
+	}, condition)
+	assert.True(t, condition.test(`{"a":1,"b":2}`))
+	assert.False(t, (&simplexCondition{operator: equalToJSON, value: `{"a":1}`}).test(`{"a":1}`))
+	assert.False(t, (&simplexCondition{operator: equalToXML, value: `<a/>`}).test(`<a/>`))
+	condition, err = buildBodyCondition(conditionDTO{"equal_to": "x"})
+	require.Nil(t, err)
+	assert.Equal(t, &simplexCondition{operator: equal, value: "x"}, condition)
+	condition, err = buildBodyCondition(nil)
+	assert.Nil(t, err)
+	assert.Nil(t, condition)
+}
+
+func TestBuildBodyConditionInvalid(t *testing.T) {
+	_, err := buildBodyCondition(conditionDTO{"equal_to_json": `{"a":`})
+	assert.Equal(t, `the value {"a": of the operator equal_to_json is not a valid json.`, err.(Error).Description)
+	_, err = buildBodyCondition(conditionDTO{"equal_to": "x", "ignore_array_order": "true"})
+	assert.Equal(t, "the body options require the equal_to_json operator.", err.(Error).Description)
+	_, err = buildBodyCondition(conditionDTO{"ignore_array_order": "true"})
+	assert.Equal(t, "the body options require the equal_to_json operator.", err.(Error).Description)
+	_, err = buildBodyCondition(conditionDTO{"equal_to_json": "{}", "ignore_array_order": "yes"})
+	assert.Equal(t, "the value yes of the option ignore_array_order is not a boolean.", err.(Error).Description)
+}
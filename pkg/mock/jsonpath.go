@@ -0,0 +1,198 @@
+package mock
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+type jsonPathStepKind uint8
+
+const (
+	stepField jsonPathStepKind = iota
+	stepIndex
+	stepWildcard
+	stepRecursive
+	stepDescendants
+)
+
+type jsonPathStep struct {
+	kind  jsonPathStepKind
+	name  string
+	index int
+}
+
+type jsonPath []jsonPathStep
+
+func compileJSONPath(expression string) (jsonPath, error) {
+	if !strings.HasPrefix(expression, "$") {
+		return nil, invalidJSONPath(expression)
+	}
+	var path jsonPath
+	rest := expression[1:]
+	for rest != "" {
+		var step jsonPathStep
+		var err error
+		switch {
+		case strings.HasPrefix(rest, ".."):
+			step, rest, err = parseDotStep(rest[2:], expression)
+			switch step.kind {
+			case stepField:
+				step.kind = stepRecursive
+			case stepWildcard:
+				step.kind = stepDescendants
+			}
+		case strings.HasPrefix(rest, "."):
+			step, rest, err = parseDotStep(rest[1:], expression)
+		case strings.HasPrefix(rest, "["):
+			step, rest, err = parseBracketStep(rest, expression)
+		default:
+			err = invalidJSONPath(expression)
+		}
+		if err != nil {
+			return nil, err
+		}
+		path = append(path, step)
+	}
+	return path, nil
+}
+
+func parseDotStep(rest string, expression string) (jsonPathStep, string, error) {
+	end := strings.IndexAny(rest, ".[")
+	if end < 0 {
+		end = len(rest)
+	}
+	name := rest[:end]
+	if name == "" {
+		return jsonPathStep{}, "", invalidJSONPath(expression)
+	}
+	if name == "*" {
+		return jsonPathStep{kind: stepWildcard}, rest[end:], nil
+	}
+	return jsonPathStep{kind: stepField, name: name}, rest[end:], nil
+}
+
+func parseBracketStep(rest string, expression string) (jsonPathStep, string, error) {
+	end := strings.Index(rest, "]")
+	if end < 0 {
+		return jsonPathStep{}, "", invalidJSONPath(expression)
+	}
+	content := strings.TrimSpace(rest[1:end])
+	rest = rest[end+1:]
+	if content == "*" {
+		return jsonPathStep{kind: stepWildcard}, rest, nil
+	}
+	if len(content) >= 2 && (content[0] == '\'' || content[0] == '"') && content[len(content)-1] == content[0] {
+		return jsonPathStep{kind: stepField, name: content[1 : len(content)-1]}, rest, nil
+	}
+	index, err := strconv.Atoi(content)
+	if err != nil {
+		return jsonPathStep{}, "", invalidJSONPath(expression)
+	}
+	return jsonPathStep{kind: stepIndex, index: index}, rest, nil
+}
+
+func invalidJSONPath(expression string) error {
+	return invalidRequest(fmt.Sprintf("the json path %s is invalid.", expression))
+}
+
+func parseJSON(body []byte) (any, bool) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var document any
+	if err := decoder.Decode(&document); err != nil {
+		return nil, false
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, false
+	}
+	return document, true
+}
+
+func (path jsonPath) evaluate(document any) []any {
+	nodes := []any{document}
+	for _, step := range path {
+		var next []any
+		for _, node := range nodes {
+			next = append(next, step.apply(node)...)
+		}
+		nodes = next
+	}
+	return nodes
+}
+
+func (step jsonPathStep) apply(node any) []any {
+	switch step.kind {
+	case stepField:
+		if object, ok := node.(map[string]any); ok {
+			if value, exists := object[step.name]; exists {
+				return []any{value}
+			}
+		}
+	case stepIndex:
+		if array, ok := node.([]any); ok {
+			index := step.index
+			if index < 0 {
+				index += len(array)
+			}
+			if index >= 0 && index < len(array) {
+				return []any{array[index]}
+			}
+		}
+	case stepWildcard:
+		return children(node)
+	case stepRecursive:
+		var results []any
+		if object, ok := node.(map[string]any); ok {
+			if value, exists := object[step.name]; exists {
+				results = append(results, value)
+			}
+		}
+		for _, child := range children(node) {
+			results = append(results, step.apply(child)...)
+		}
+		return results
+	case stepDescendants:
+		var results []any
+		for _, child := range children(node) {
+			results = append(results, child)
+			results = append(results, step.apply(child)...)
+		}
+		return results
+	}
+	return nil
+}
+
+func children(node any) []any {
+	switch value := node.(type) {
+	case map[string]any:
+		var results []any
+		for _, child := range value {
+			results = append(results, child)
+		}
+		return results
+	case []any:
+		return value
+	default:
+		return nil
+	}
+}
+
+func jsonValueAsString(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	case nil:
+		return "null"
+	default:
+		data, _ := json.Marshal(v)
+		return string(data)
+	}
+}
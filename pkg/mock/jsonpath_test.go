@@ -0,0 +1,94 @@
+package mock
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const jsonPathDocument = `{"user":{"name":"pedro","age":30,"active":true,"tags":["a","b"],` +
+	`"address":{"city":"Bogota"}},"items":[{"id":1,"name":"pen"},{"id":2,"name":"book"}],"note":null}`
+
+func evaluateJSONPath(t *testing.T, expression string) []string {
+	path, err := compileJSONPath(expression)
+	require.Nil(t, err)
+	document, valid := parseJSON([]byte(jsonPathDocument))
+	require.True(t, valid)
+	var values []string
+	for _, value := range path.evaluate(document) {
+		values = append(values, jsonValueAsString(value))
+	}
+	return values
+}
+
+func TestJSONPathEvaluate(t *testing.T) {
+	assert.Equal(t, []string{"pedro"}, evaluateJSONPath(t, "$.user.name"))
+	assert.Equal(t, []string{"30"}, evaluateJSONPath(t, "$.user.age"))
+	assert.Equal(t, []string{"true"}, evaluateJSONPath(t, "$.user.active"))
+	assert.Equal(t, []string{"null"}, evaluateJSONPath(t, "$.note"))
+	assert.Equal(t, []string{"Bogota"}, evaluateJSONPath(t, "$['user']['address'][\"city\"]"))
+	assert.Equal(t, []string{"b"}, evaluateJSONPath(t, "$.user.tags[1]"))
+	assert.Equal(t, []string{"b"}, evaluateJSONPath(t, "$.user.tags[-1]"))
+	assert.Equal(t, []string{"a", "b"}, evaluateJSONPath(t, "$.user.tags[*]"))
+	assert.Equal(t, []string{"1", "2"}, evaluateJSONPath(t, "$.items.*.id"))
+	assert.ElementsMatch(t, []string{"pedro", "pen", "book"}, evaluateJSONPath(t, "$..name"))
+	assert.Equal(t, []string{`["a","b"]`}, evaluateJSONPath(t, "$.user.tags"))
+	assert.Contains(t, evaluateJSONPath(t, "$..*"), "Bogota")
+	assert.Nil(t, evaluateJSONPath(t, "$.user.missing"))
+	assert.Nil(t, evaluateJSONPath(t, "$.user.tags[5]"))
+}
+
+func TestJSONPathInvalidExpression(t *testing.T) {
+	for _, expression := range []string{"user.name", "$.", "$[abc]", "$[1", "$user"} {
+		_, err := compileJSONPath(expression)
+		assert.Error(t, err, expression)
+		assert.Equal(t, "the json path "+expression+" is invalid.", err.(Error).Description)
+	}
+}
+
+func TestJSONPathConditionsMatch(t *testing.T) {
+	conditions, err := buildJSONPathConditions(map[string]conditionDTO{
+		"$.user.name":   {"equal_to": "pedro"},
+		"$.items[*].id": {"equal_to": "2"},
+		"$.user.tags":   {"exists": ""},
+	})
+	require.Nil(t, err)
+	reqMatch := requestMatch{BodyJSONPath: conditions}
+	assert.True(t, reqMatch.IsExpected(httpRequest{Body: []byte(jsonPathDocument)}))
+	assert.False(t, reqMatch.IsExpected(httpRequest{Body: []byte(`{"user":{"name":"pedro"}}`)}))
+	assert.False(t, reqMatch.IsExpected(httpRequest{Body: []byte(`not json`)}))
+	assert.False(t, reqMatch.IsExpected(httpRequest{Body: []byte(jsonPathDocument + ` garbage`)}))
+}
+
+func TestJSONPathConditionsEvaluate(t *testing.T) {
+	conditions, err := buildJSONPathConditions(map[string]conditionDTO{
+		"$.user.name": {"equal_to": "juan"},
+	})
+	require.Nil(t, err)
+	reqMatch := requestMatch{BodyJSONPath: conditions}
+	results := reqMatch.evaluate(httpRequest{Body: []byte(jsonPathDocument)})
+	assert.Equal(t, []fieldMatch{{
+		Field:  "body json path $.user.name",
+		Reason: `body json path $.user.name does not match equal_to "juan"`,
+	}}, results)
+	results = reqMatch.evaluate(httpRequest{Body: []byte(`{}`)})
+	assert.Equal(t, "body json path $.user.name is missing", results[0].Reason)
+	results = reqMatch.evaluate(httpRequest{Body: []byte(`nope`)})
+	assert.Equal(t, "body is not a valid json", results[0].Reason)
+}
+
+func TestBuildJSONPathConditionsInvalid(t *testing.T) {
+	_, err := buildJSONPathConditions(map[string]conditionDTO{"name": {"equal_to": "pedro"}})
+	assert.Error(t, err)
+	_, err = buildJSONPathConditions(map[string]conditionDTO{"$.name": {"unknown": "pedro"}})
+	assert.Error(t, err)
+}
+
+func TestJSONPathConditionsAbsent(t *testing.T) {
+	conditions, err := buildJSONPathConditions(map[string]conditionDTO{"$.user.password": Absent()})
+	require.Nil(t, err)
+	reqMatch := requestMatch{BodyJSONPath: conditions}
+	assert.True(t, reqMatch.IsExpected(httpRequest{Body: []byte(jsonPathDocument)}))
+	assert.False(t, reqMatch.IsExpected(httpRequest{Body: []byte(`{"user":{"password":"secret"}}`)}))
+}
@@ -1,42 +1,313 @@
 package mock
 
-import "fmt"
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+)
 
 type Mocker interface {
 	When(req *requestDTO) Expect
+	Verify(req *requestDTO) Verification
+	Requests() []JournalEntry
+	FindRequests(req *requestDTO) ([]JournalEntry, error)
+	ClearRequests()
+	Remove(id string) error
+	Reset() error
+	Scoped(t TestingT) Mocker
+	Scenarios() []ScenarioState
+	ScenarioState(name string) (string, error)
+	SetScenarioState(name string, state string) error
+	ResetScenarios()
+}
+
+type TestingT interface {
+	Cleanup(func())
 }
 
 type Expect interface {
-	ThenReturn(resp *responseDTO) error
+	InScenario(name string) Expect
+	WhenScenarioStateIs(state string) Expect
+	WillSetStateTo(state string) Expect
+	Looping() Expect
+	ThenReturn(resp ...*responseDTO) error
+	ThenReturnStub(resp ...*responseDTO) (Stub, error)
+	ThenRespond(responder Responder) error
+	ThenHandle(handler http.HandlerFunc) error
+}
+
+type Verification interface {
+	Times(expected int) error
+	AtLeast(expected int) error
+	Never() error
 }
 
 type mocker struct {
 	service Service
+	scope   *scope
 }
 type expect struct {
-	req     *requestDTO
-	service Service
+	req      *requestDTO
+	scenario *scenarioDTO
+	mode     string
+	service  Service
+	scope    *scope
+}
+
+type scope struct {
+	mutex sync.Mutex
+	ids   []string
 }
 
 func (m *mocker) When(req *requestDTO) Expect {
 	return &expect{
 		service: m.service,
 		req:     req,
+		scope:   m.scope,
+	}
+}
+func (m *mocker) Verify(req *requestDTO) Verification {
+	return &verification{
+		service: m.service,
+		req:     req,
+	}
+}
+
+func (m *mocker) Requests() []JournalEntry {
+	return m.service.Requests()
+}
+
+func (m *mocker) FindRequests(req *requestDTO) ([]JournalEntry, error) {
+	if req == nil {
+		return nil, fmt.Errorf("the request builder to find could not be nil")
+	}
+	return m.service.FindRequests(req)
+}
+
+func (m *mocker) ClearRequests() {
+	m.service.ClearRequests()
+}
+
+func (m *mocker) Scenarios() []ScenarioState {
+	return m.service.Scenarios()
+}
+
+func (m *mocker) ScenarioState(name string) (string, error) {
+	return m.service.ScenarioState(name)
+}
+
+func (m *mocker) SetScenarioState(name string, state string) error {
+	return m.service.SetScenarioState(name, state)
+}
+
+func (m *mocker) ResetScenarios() {
+	m.service.ResetScenarios()
+}
+
+func (m *mocker) Remove(id string) error {
+	return m.service.Delete(id)
+}
+
+func (m *mocker) Reset() error {
+	if m.scope != nil {
+		return m.scope.removeAll(m.service)
+	}
+	return m.service.DeleteAll()
+}
+
+func (m *mocker) Scoped(t TestingT) Mocker {
+	scoped := &mocker{
+		service: m.service,
+		scope:   &scope{},
+	}
+	t.Cleanup(func() {
+		err := scoped.scope.removeAll(scoped.service)
+		if err != nil {
+			LogError("error removing scoped mappings, error: %v", err)
+		}
+	})
+	return scoped
+}
+
+func (s *scope) track(id string) {
+	if s == nil {
+		return
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.ids = append(s.ids, id)
+}
+
+func (s *scope) removeAll(service Service) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	var errs []error
+	for _, id := range s.ids {
+		err := service.Delete(id)
+		var domainErr Error
+		if err != nil && !(errors.As(err, &domainErr) && domainErr.Code == mappingNotFoundCode) {
+			errs = append(errs, err)
+		}
+	}
+	s.ids = nil
+	return errors.Join(errs...)
+}
+
+func (exp *expect) InScenario(name string) Expect {
+	exp.getScenario().Name = name
+	return exp
+}
+
+func (exp *expect) WhenScenarioStateIs(state string) Expect {
+	exp.getScenario().RequiredState = state
+	return exp
+}
+
+func (exp *expect) WillSetStateTo(state string) Expect {
+	exp.getScenario().NewState = state
+	return exp
+}
+
+func (exp *expect) getScenario() *scenarioDTO {
+	if exp.scenario == nil {
+		exp.scenario = &scenarioDTO{}
+	}
+	return exp.scenario
+}
+
+func (exp *expect) Looping() Expect {
+	exp.mode = responsesModeLoop
+	return exp
+}
+
+func (exp *expect) ThenReturn(resp ...*responseDTO) error {
+	_, err := exp.ThenReturnStub(resp...)
+	return err
+}
+
+func (exp *expect) ThenRespond(responder Responder) error {
+	if responder == nil {
+		return fmt.Errorf("the responder could not be nil")
+	}
+	_, err := exp.register(&callback{responder: responder})
+	return err
+}
+
+func (exp *expect) ThenHandle(handler http.HandlerFunc) error {
+	if handler == nil {
+		return fmt.Errorf("the handler could not be nil")
+	}
+	_, err := exp.register(&callback{handler: handler})
+	return err
+}
+
+func (exp *expect) ThenReturnStub(resp ...*responseDTO) (Stub, error) {
+	if exp.req == nil {
+		return nil, fmt.Errorf("the request builder expected could not be nil")
+	}
+	mock, err := withResponses(exp.definition(), resp)
+	if err != nil {
+		return nil, err
 	}
+	return exp.add(mock)
 }
-func (exp *expect) ThenReturn(resp *responseDTO) error {
+
+func (exp *expect) register(responder *callback) (Stub, error) {
 	if exp.req == nil {
-		return fmt.Errorf("the request builder expected could not be nil")
+		return nil, fmt.Errorf("the request builder expected could not be nil")
 	}
-	if resp == nil {
-		return fmt.Errorf("the response builder could not be nil")
+	mock := exp.definition()
+	mock.callback = responder
+	return exp.add(mock)
+}
+
+func (exp *expect) definition() mockDTO {
+	return mockDTO{
+		Request:       exp.req,
+		Scenario:      exp.scenario,
+		ResponsesMode: exp.mode,
 	}
-	mock := mockDTO{
-		Request:  exp.req,
-		Response: resp,
+}
+
+func (exp *expect) add(definition mockDTO) (Stub, error) {
+	added, err := exp.service.Add(definition)
+	var created Stub
+	if err == nil {
+		exp.scope.track(added.ID)
+		created = &stub{
+			id:         added.ID,
+			definition: exp.definition(),
+			service:    exp.service,
+		}
 	}
-	_, err := exp.service.Add(mock)
 	exp.req = nil
 	exp.service = nil
-	return err
+	return created, err
+}
+
+func withResponses(mock mockDTO, responses []*responseDTO) (mockDTO, error) {
+	if len(responses) < 1 {
+		return mock, fmt.Errorf("the response builder could not be nil")
+	}
+	for _, response := range responses {
+		if response == nil {
+			return mock, fmt.Errorf("the response builder could not be nil")
+		}
+	}
+	mock.callback = nil
+	if len(responses) == 1 {
+		mock.Response = responses[0]
+	} else {
+		mock.Responses = responses
+	}
+	return mock, nil
+}
+
+type verification struct {
+	req       *requestDTO
+	mappingID string
+	service   Service
+}
+
+func (v *verification) Times(expected int) error {
+	return v.check(expected, "exactly", func(count int) bool { return count == expected })
+}
+
+func (v *verification) AtLeast(expected int) error {
+	return v.check(expected, "at least", func(count int) bool { return count >= expected })
+}
+
+func (v *verification) Never() error {
+	return v.check(0, "exactly", func(count int) bool { return count == 0 })
+}
+
+func (v *verification) check(expected int, quantifier string, predicate func(int) bool) error {
+	if v.mappingID != "" {
+		count := v.service.CountByMapping(v.mappingID)
+		if !predicate(count) {
+			return fmt.Errorf("expected %s %d request(s) served by mapping %s but received %d", quantifier, expected, v.mappingID, count)
+		}
+		return nil
+	}
+	if v.req == nil {
+		return fmt.Errorf("the request builder to verify could not be nil")
+	}
+	count, err := v.service.Count(v.req)
+	if err != nil {
+		return err
+	}
+	if !predicate(count) {
+		return fmt.Errorf("expected %s %d request(s) matching %s but received %d", quantifier, expected, describe(v.req), count)
+	}
+	return nil
+}
+
+func describe(req *requestDTO) string {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Sprintf("%v", *req)
+	}
+	return string(data)
 }
@@ -22,6 +22,25 @@ func (r *repositoryMock) GetAll() []mock {
 	return args[0].([]mock)
 }
 
+func (r *repositoryMock) Get(id string) (*mock, error) {
+	args := r.Called(id)
+	var r1 *mock
+	if args.Get(0) != nil {
+		r1 = args.Get(0).(*mock)
+	}
+	return r1, args.Error(1)
+}
+
+func (r *repositoryMock) Delete(id string) error {
+	args := r.Called(id)
+	return args.Error(0)
+}
+
+func (r *repositoryMock) DeleteAll() error {
+	args := r.Called()
+	return args.Error(0)
+}
+
 type serviceMock struct {
 	mocking.Mock
 }
@@ -44,6 +63,115 @@ func (r *serviceMock) Match(request httpRequest) (*httpResponse, error) {
 	return r1, args.Error(1)
 }
 
+func (r *serviceMock) Count(request *requestDTO) (int, error) {
+	args := r.Called(request)
+	return args.Int(0), args.Error(1)
+}
+
+func (r *serviceMock) CountByMapping(id string) int {
+	args := r.Called(id)
+	return args.Int(0)
+}
+
+func (r *serviceMock) Requests() []JournalEntry {
+	args := r.Called()
+	if args[0] == nil {
+		return nil
+	}
+	return args[0].([]JournalEntry)
+}
+
+func (r *serviceMock) FindRequests(request *requestDTO) ([]JournalEntry, error) {
+	args := r.Called(request)
+	var r1 []JournalEntry
+	if args.Get(0) != nil {
+		r1 = args.Get(0).([]JournalEntry)
+	}
+	return r1, args.Error(1)
+}
+
+func (r *serviceMock) ClearRequests() {
+	r.Called()
+}
+
+func (r *serviceMock) List() []mockDTO {
+	args := r.Called()
+	if args[0] == nil {
+		return nil
+	}
+	return args[0].([]mockDTO)
+}
+
+func (r *serviceMock) Get(id string) (*mockDTO, error) {
+	args := r.Called(id)
+	var r1 *mockDTO
+	if args.Get(0) != nil {
+		r1 = args.Get(0).(*mockDTO)
+	}
+	return r1, args.Error(1)
+}
+
+func (r *serviceMock) Update(id string, mock mockDTO) (*addMockResponse, error) {
+	args := r.Called(id, mock)
+	var r1 *addMockResponse
+	if args.Get(0) != nil {
+		r1 = args.Get(0).(*addMockResponse)
+	}
+	return r1, args.Error(1)
+}
+
+func (r *serviceMock) Delete(id string) error {
+	args := r.Called(id)
+	return args.Error(0)
+}
+
+func (r *serviceMock) DeleteAll() error {
+	args := r.Called()
+	return args.Error(0)
+}
+
+func (r *serviceMock) Scenarios() []ScenarioState {
+	args := r.Called()
+	if args[0] == nil {
+		return nil
+	}
+	return args[0].([]ScenarioState)
+}
+
+func (r *serviceMock) ScenarioState(name string) (string, error) {
+	args := r.Called(name)
+	return args.String(0), args.Error(1)
+}
+
+func (r *serviceMock) SetScenarioState(name string, state string) error {
+	args := r.Called(name, state)
+	return args.Error(0)
+}
+
+func (r *serviceMock) ResetScenarios() {
+	r.Called()
+}
+
+type journalMock struct {
+	mocking.Mock
+}
+
+func (j *journalMock) Record(entry journalEntry) {
+	j.Called(entry)
+}
+
+func (j *journalMock) GetAll() []journalEntry {
+	args := j.Called()
+	if args[0] == nil {
+		return nil
+	}
+	return args[0].([]journalEntry)
+}
+
+func (j *journalMock) Clear() {
+	j.Called()
+}
+
 type responseWriterMock struct {
 	mocking.Mock
 }
@@ -0,0 +1,58 @@
+package mock
+
+import "sort"
+
+const (
+	matchRuleSingle      = "single_match"
+	matchRulePriority    = "priority"
+	matchRuleSpecificity = "specificity"
+	matchRuleMostRecent  = "most_recent"
+	matchRuleID          = "mapping_id"
+)
+
+func selectMapping(aggregates []mock) (mock, string) {
+	sort.SliceStable(aggregates, func(i, j int) bool {
+		return aggregates[i].precedes(aggregates[j])
+	})
+	if len(aggregates) < 2 {
+		return aggregates[0], matchRuleSingle
+	}
+	first, second := aggregates[0], aggregates[1]
+	switch {
+	case first.Request.Priority != second.Request.Priority:
+		return first, matchRulePriority
+	case first.Request.specificity() != second.Request.specificity():
+		return first, matchRuleSpecificity
+	case first.sequence != second.sequence:
+		return first, matchRuleMostRecent
+	default:
+		return first, matchRuleID
+	}
+}
+
+func (aggregate mock) precedes(other mock) bool {
+	if aggregate.Request.Priority != other.Request.Priority {
+		return aggregate.Request.Priority > other.Request.Priority
+	}
+	if specificity, otherSpecificity := aggregate.Request.specificity(), other.Request.specificity(); specificity != otherSpecificity {
+		return specificity > otherSpecificity
+	}
+	if aggregate.sequence != other.sequence {
+		return aggregate.sequence > other.sequence
+	}
+	return aggregate.ID < other.ID
+}
+
+func (match *requestMatch) specificity() int {
+	score := len(match.Headers) + len(match.QueryParameters) + len(match.Cookies) + len(match.PathParameters) +
+		len(match.BodyJSONPath) + len(match.BodyXPath)
+	for _, condition := range []*simplexCondition{match.URL, match.Body} {
+		if condition != nil {
+			score++
+		}
+	}
+	if match.Method != nil {
+		score++
+	}
+	return score
+}
@@ -0,0 +1,69 @@
+package mock
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelectMappingSingle(t *testing.T) {
+	aggregate, rule := selectMapping([]mock{{ID: "1"}})
+	assert.Equal(t, "1", aggregate.ID)
+	assert.Equal(t, matchRuleSingle, rule)
+}
+
+func TestSelectMappingByPriority(t *testing.T) {
+	method := getMethod
+	aggregate, rule := selectMapping([]mock{
+		{ID: "1", Request: requestMatch{Method: &method}, sequence: 2},
+		{ID: "2", Request: requestMatch{Priority: 10}, sequence: 1},
+	})
+	assert.Equal(t, "2", aggregate.ID)
+	assert.Equal(t, matchRulePriority, rule)
+}
+
+func TestSelectMappingBySpecificity(t *testing.T) {
+	method := getMethod
+	aggregate, rule := selectMapping([]mock{
+		{ID: "1", Request: requestMatch{URL: &simplexCondition{operator: contains, value: "test"}}, sequence: 2},
+		{ID: "2", Request: requestMatch{
+			URL:     &simplexCondition{operator: contains, value: "test"},
+			Method:  &method,
+			Headers: complexConditions{{field: "Accept", simplexCondition: simplexCondition{operator: equal, value: "json"}}},
+		}, sequence: 1},
+	})
+	assert.Equal(t, "2", aggregate.ID)
+	assert.Equal(t, matchRuleSpecificity, rule)
+}
+
+func TestSelectMappingByMostRecent(t *testing.T) {
+	aggregate, rule := selectMapping([]mock{
+		{ID: "1", sequence: 1},
+		{ID: "2", sequence: 3},
+		{ID: "3", sequence: 2},
+	})
+	assert.Equal(t, "2", aggregate.ID)
+	assert.Equal(t, matchRuleMostRecent, rule)
+}
+
+func TestSelectMappingByID(t *testing.T) {
+	aggregate, rule := selectMapping([]mock{{ID: "b"}, {ID: "a"}})
+	assert.Equal(t, "a", aggregate.ID)
+	assert.Equal(t, matchRuleID, rule)
+}
+
+func TestSpecificity(t *testing.T) {
+	method := getMethod
+	match := requestMatch{
+		URL:             &simplexCondition{operator: equal, value: "/test"},
+		Method:          &method,
+		Headers:         complexConditions{{field: "Accept"}, {field: "Authorization"}},
+		QueryParameters: complexConditions{{field: "page"}},
+		Cookies:         complexConditions{{field: "session"}},
+		Body:            &simplexCondition{operator: contains, value: "name"},
+		BodyJSONPath:    jsonPathConditions{{}},
+		BodyXPath:       xPathConditions{{}},
+	}
+	assert.Equal(t, 9, match.specificity())
+	assert.Equal(t, 0, (&requestMatch{Priority: 1}).specificity())
+}
@@ -3,35 +3,89 @@ package mock
 import (
 	"encoding/json"
 	"fmt"
+	"net/textproto"
+	"strconv"
+	"time"
 
 	"github.com/google/uuid"
 )
 
 const (
-	operatorEqual    = "equal_to"
-	operatorContains = "contains"
-	operatorPattern  = "pattern"
+	operatorEqual              = "equal_to"
+	operatorContains           = "contains"
+	operatorPattern            = "pattern"
+	operatorExists             = "exists"
+	operatorEqualToJSON        = "equal_to_json"
+	operatorEqualToXML         = "equal_to_xml"
+	operatorAnd                = "and"
+	operatorOr                 = "or"
+	operatorNot                = "not"
+	operatorAbsent             = "absent"
+	operatorPresent            = "present"
+	operatorEqualIgnoreCase    = "equal_to_ignore_case"
+	operatorContainsIgnoreCase = "contains_ignore_case"
+	operatorHasItem            = "has_item"
+	operatorIncludesAll        = "includes_all"
+	operatorExactList          = "exact_list"
+	operatorURLTemplate        = "url_template"
+)
+
+const (
+	responsesModeRepeatLast = "repeat_last"
+	responsesModeLoop       = "loop"
 )
 
 type mockDTO struct {
-	ID       string       `json:"id"`
-	Request  *requestDTO  `json:"request"`
-	Response *responseDTO `json:"response"`
+	ID            string         `json:"id"`
+	Request       *requestDTO    `json:"request"`
+	Response      *responseDTO   `json:"response,omitempty"`
+	Responses     []*responseDTO `json:"responses,omitempty"`
+	ResponsesMode string         `json:"responses_mode,omitempty"`
+	Scenario      *scenarioDTO   `json:"scenario,omitempty"`
+	callback      *callback
+}
+
+type scenarioDTO struct {
+	Name          string `json:"name"`
+	RequiredState string `json:"required_state,omitempty"`
+	NewState      string `json:"new_state,omitempty"`
 }
 
 type requestDTO struct {
-	URL             map[string]string            `json:"url"`
-	Method          *string                      `json:"method"`
-	Headers         map[string]map[string]string `json:"headers"`
-	QueryParameters map[string]map[string]string `json:"query_parameters"`
-	Priority        int                          `json:"priority"`
-	Body            map[string]string            `json:"body"`
+	URL             conditionDTO            `json:"url,omitempty"`
+	Method          *string                 `json:"method,omitempty"`
+	Headers         map[string]conditionDTO `json:"headers,omitempty"`
+	QueryParameters map[string]conditionDTO `json:"query_parameters,omitempty"`
+	Cookies         map[string]conditionDTO `json:"cookies,omitempty"`
+	PathParameters  map[string]conditionDTO `json:"path_parameters,omitempty"`
+	Priority        int                     `json:"priority,omitempty"`
+	Body            conditionDTO            `json:"body,omitempty"`
+	BodyJSONPath    map[string]conditionDTO `json:"body_json_path,omitempty"`
+	BodyXPath       map[string]conditionDTO `json:"body_xpath,omitempty"`
 }
 
 type responseDTO struct {
-	Status  int               `json:"status"`
-	Body    json.RawMessage   `json:"body"`
-	Headers map[string]string `json:"headers"`
+	Status              int                     `json:"status"`
+	Body                json.RawMessage         `json:"body"`
+	Headers             map[string]string       `json:"headers"`
+	FixedDelay          int64                   `json:"fixed_delay_ms,omitempty"`
+	DelayDistribution   *delayDistributionDTO   `json:"delay_distribution,omitempty"`
+	ChunkedDribbleDelay *chunkedDribbleDelayDTO `json:"chunked_dribble_delay,omitempty"`
+	Fault               string                  `json:"fault,omitempty"`
+	Template            bool                    `json:"template,omitempty"`
+}
+
+type delayDistributionDTO struct {
+	Type   string  `json:"type"`
+	Lower  int64   `json:"lower_ms,omitempty"`
+	Upper  int64   `json:"upper_ms,omitempty"`
+	Median int64   `json:"median_ms,omitempty"`
+	Sigma  float64 `json:"sigma,omitempty"`
+}
+
+type chunkedDribbleDelayDTO struct {
+	NumberOfChunks int   `json:"number_of_chunks"`
+	TotalDuration  int64 `json:"total_duration_ms"`
 }
 
 type addMockResponse struct {
@@ -42,7 +96,7 @@ func (dto mockDTO) toAggregate() (*mock, error) {
 	if dto.Request == nil {
 		return nil, invalidRequest("the mock request could not be a null")
 	}
-	if dto.Response == nil {
+	if dto.Response == nil && len(dto.Responses) < 1 && dto.callback == nil {
 		return nil, invalidRequest("the mock response could not be a null")
 	}
 	id := dto.ID
@@ -54,23 +108,136 @@ func (dto mockDTO) toAggregate() (*mock, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &mock{
-		ID:      id,
-		Request: *request,
-		Response: httpResponse{
-			Status:  dto.Response.Status,
-			Body:    dto.Response.Body,
-			Headers: dto.Response.Headers,
-		},
+	dto.ID = id
+	aggregate := &mock{
+		ID:         id,
+		Request:    *request,
+		Scenario:   dto.Scenario.toScenario(),
+		definition: dto,
+	}
+	if dto.callback != nil {
+		aggregate.Response = httpResponse{callback: dto.callback}
+		return aggregate, nil
+	}
+	if dto.Response != nil {
+		response, err := dto.Response.toHttpResponse()
+		if err != nil {
+			return nil, err
+		}
+		aggregate.Response = *response
+		return aggregate, nil
+	}
+	var responses []httpResponse
+	for _, dtoResponse := range dto.Responses {
+		if dtoResponse == nil {
+			return nil, invalidRequest("the mock responses could not contain a null")
+		}
+		response, err := dtoResponse.toHttpResponse()
+		if err != nil {
+			return nil, err
+		}
+		responses = append(responses, *response)
+	}
+	aggregate.Response = responses[0]
+	aggregate.Sequence = newResponseSequence(responses, dto.ResponsesMode == responsesModeLoop)
+	return aggregate, nil
+}
+
+func (dto *responseDTO) toHttpResponse() (*httpResponse, error) {
+	if dto.FixedDelay < 0 {
+		return nil, invalidRequest("the fixed delay could not be negative")
+	}
+	randomDelay, err := dto.DelayDistribution.toDelayDistribution()
+	if err != nil {
+		return nil, err
+	}
+	chunkedDribble, err := dto.ChunkedDribbleDelay.toChunkedDribbleDelay()
+	if err != nil {
+		return nil, err
+	}
+	fault, err := toFault(dto.Fault)
+	if err != nil {
+		return nil, err
+	}
+	var responseTemplate *responseTemplate
+	if dto.Template {
+		responseTemplate, err = newResponseTemplate(dto.Body, dto.Headers)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &httpResponse{
+		Status:         dto.Status,
+		Body:           dto.Body,
+		Headers:        dto.Headers,
+		FixedDelay:     time.Duration(dto.FixedDelay) * time.Millisecond,
+		RandomDelay:    randomDelay,
+		ChunkedDribble: chunkedDribble,
+		Fault:          fault,
+		Template:       responseTemplate,
+	}, nil
+}
+
+func (dto *delayDistributionDTO) toDelayDistribution() (delayDistribution, error) {
+	if dto == nil {
+		return nil, nil
+	}
+	switch dto.Type {
+	case delayUniform:
+		if dto.Lower < 0 || dto.Upper < dto.Lower {
+			return nil, invalidRequest("the uniform delay requires 0 <= lower_ms <= upper_ms")
+		}
+		return uniformDelay{
+			lower: time.Duration(dto.Lower) * time.Millisecond,
+			upper: time.Duration(dto.Upper) * time.Millisecond,
+		}, nil
+	case delayLognormal:
+		if dto.Median <= 0 || dto.Sigma < 0 {
+			return nil, invalidRequest("the lognormal delay requires a positive median_ms and a non negative sigma")
+		}
+		return lognormalDelay{
+			median: time.Duration(dto.Median) * time.Millisecond,
+			sigma:  dto.Sigma,
+		}, nil
+	default:
+		return nil, invalidRequest(fmt.Sprintf("the delay distribution %s is not supported.", dto.Type))
+	}
+}
+
+func (dto *chunkedDribbleDelayDTO) toChunkedDribbleDelay() (*chunkedDribbleDelay, error) {
+	if dto == nil {
+		return nil, nil
+	}
+	if dto.NumberOfChunks < 1 || dto.TotalDuration < 0 {
+		return nil, invalidRequest("the chunked dribble delay requires at least one chunk and a non negative total_duration_ms")
+	}
+	return &chunkedDribbleDelay{
+		chunks: dto.NumberOfChunks,
+		total:  time.Duration(dto.TotalDuration) * time.Millisecond,
 	}, nil
 }
 
+func (dto *scenarioDTO) toScenario() *scenario {
+	if dto == nil {
+		return nil
+	}
+	return &scenario{
+		name:          dto.Name,
+		requiredState: dto.RequiredState,
+		newState:      dto.NewState,
+	}
+}
+
 func toRequestMatch(dto mockDTO) (*requestMatch, error) {
-	urlCondition, err := buildSimplexConditionFromMap(dto.Request.URL)
+	urlCondition, err := buildCondition(dto.Request.URL)
 	if err != nil {
 		return nil, err
 	}
-	headers, err := buildComplexCondition(dto.Request.Headers)
+	pathParameters, err := buildPathParameterConditions(urlCondition, dto.Request.PathParameters)
+	if err != nil {
+		return nil, err
+	}
+	headers, err := buildHeaderConditions(dto.Request.Headers)
 	if err != nil {
 		return nil, err
 	}
@@ -78,7 +245,19 @@ func toRequestMatch(dto mockDTO) (*requestMatch, error) {
 	if err != nil {
 		return nil, err
 	}
-	body, err := buildSimplexConditionFromMap(dto.Request.Body)
+	cookies, err := buildComplexCondition(dto.Request.Cookies)
+	if err != nil {
+		return nil, err
+	}
+	body, err := buildBodyCondition(dto.Request.Body)
+	if err != nil {
+		return nil, err
+	}
+	bodyJSONPath, err := buildJSONPathConditions(dto.Request.BodyJSONPath)
+	if err != nil {
+		return nil, err
+	}
+	bodyXPath, err := buildXPathConditions(dto.Request.BodyXPath)
 	if err != nil {
 		return nil, err
 	}
@@ -88,66 +267,170 @@ func toRequestMatch(dto mockDTO) (*requestMatch, error) {
 		Method:          dto.Request.Method,
 		Headers:         headers,
 		QueryParameters: queryParams,
+		Cookies:         cookies,
+		PathParameters:  pathParameters,
 		Priority:        dto.Request.Priority,
 		Body:            body,
+		BodyJSONPath:    bodyJSONPath,
+		BodyXPath:       bodyXPath,
 	}, nil
 }
 
-func buildComplexCondition(headers map[string]map[string]string) (complexConditions, error) {
+func buildComplexCondition(fields map[string]conditionDTO) (complexConditions, error) {
 	var conditionSlice complexConditions
-	for field, rawCondition := range headers {
-		rawOperator, value := getFirstMapEntry(rawCondition)
-		if rawOperator == nil || value == nil {
+	for field, dto := range fields {
+		condition, err := buildCondition(dto)
+		if err != nil {
+			return nil, err
+		}
+		if condition == nil {
 			return nil, invalidRequest(fmt.Sprintf("the field %s has not any condition.", field))
 		}
-		op := fromString(*rawOperator)
-		if op == undefined {
-			return nil, invalidRequest(fmt.Sprintf("the operator %s is not supported.", *rawOperator))
+		conditionSlice = append(conditionSlice, complexCondition{
+			simplexCondition: *condition,
+			field:            field,
+		})
+	}
+	return conditionSlice, nil
+}
+
+func buildPathParameterConditions(url *simplexCondition, fields map[string]conditionDTO) (complexConditions, error) {
+	names := map[string]bool{}
+	if url != nil {
+		_ = url.walk(func(condition *simplexCondition) error {
+			for _, name := range condition.template.parameters() {
+				names[name] = true
+			}
+			return nil
+		})
+	}
+	conditions, err := buildComplexCondition(fields)
+	if err != nil {
+		return nil, err
+	}
+	for _, condition := range conditions {
+		if !names[condition.field] {
+			return nil, invalidRequest(fmt.Sprintf("the path parameter %s is not defined in the url template.", condition.field))
 		}
-		condition := complexCondition{
-			simplexCondition: simplexCondition{
-				operator: op,
-				value:    *value,
-			},
-			field: field,
+	}
+	return conditions, nil
+}
+
+func buildHeaderConditions(fields map[string]conditionDTO) (complexConditions, error) {
+	conditions, err := buildComplexCondition(fields)
+	if err != nil {
+		return nil, err
+	}
+	for i := range conditions {
+		conditions[i].field = textproto.CanonicalMIMEHeaderKey(conditions[i].field)
+	}
+	return conditions, nil
+}
+
+func buildJSONPathConditions(expressions map[string]conditionDTO) (jsonPathConditions, error) {
+	conditions, err := buildComplexCondition(expressions)
+	if err != nil {
+		return nil, err
+	}
+	var jsonPathSlice jsonPathConditions
+	for _, condition := range conditions {
+		path, err := compileJSONPath(condition.field)
+		if err != nil {
+			return nil, err
 		}
-		conditionSlice = append(conditionSlice, condition)
+		jsonPathSlice = append(jsonPathSlice, jsonPathCondition{
+			complexCondition: condition,
+			path:             path,
+		})
 	}
-	return conditionSlice, nil
+	return jsonPathSlice, nil
 }
 
-func buildSimplexConditionFromMap(data map[string]string) (*simplexCondition, error) {
-	key, value := getFirstMapEntry(data)
-	return buildSimpleCondition(key, value)
+func buildXPathConditions(expressions map[string]conditionDTO) (xPathConditions, error) {
+	conditions, err := buildComplexCondition(expressions)
+	if err != nil {
+		return nil, err
+	}
+	var xPathSlice xPathConditions
+	for _, condition := range conditions {
+		path, err := compileXPath(condition.field)
+		if err != nil {
+			return nil, err
+		}
+		xPathSlice = append(xPathSlice, xPathCondition{
+			complexCondition: condition,
+			path:             path,
+		})
+	}
+	return xPathSlice, nil
 }
 
-func buildSimpleCondition(key *string, value *string) (*simplexCondition, error) {
-	if key == nil || value == nil {
+func buildBodyCondition(data conditionDTO) (*simplexCondition, error) {
+	var options jsonEqualityOptions
+	rest := conditionDTO{}
+	for key, value := range data {
+		var target *bool
+		switch key {
+		case optionIgnoreExtraFields:
+			target = &options.ignoreExtraFields
+		case optionIgnoreArrayOrder:
+			target = &options.ignoreArrayOrder
+		default:
+			rest[key] = value
+			continue
+		}
+		enabled, err := strconv.ParseBool(fmt.Sprint(value))
+		if err != nil {
+			return nil, invalidRequest(fmt.Sprintf("the value %v of the option %s is not a boolean.", value, key))
+		}
+		*target = enabled
+	}
+	condition, err := buildCondition(rest)
+	if err != nil {
+		return nil, err
+	}
+	if condition == nil {
+		if options.isSet() {
+			return nil, invalidRequest(fmt.Sprintf("the body options require the %s operator.", operatorEqualToJSON))
+		}
 		return nil, nil
 	}
-	op := fromString(*key)
-	if op == undefined {
-		return nil, invalidRequest(fmt.Sprintf("the operator %s is not supported.", *key))
+	comparesJSON := false
+	_ = condition.walk(func(leaf *simplexCondition) error {
+		if leaf.operator == equalToJSON {
+			leaf.jsonOptions = options
+			comparesJSON = true
+		}
+		return nil
+	})
+	if options.isSet() && !comparesJSON {
+		return nil, invalidRequest(fmt.Sprintf("the body options require the %s operator.", operatorEqualToJSON))
 	}
-	return &simplexCondition{
-		operator: op,
-		value:    *value,
-	}, nil
+	return condition, nil
 }
 
 type requestBuilder struct {
 	method          *string
-	body            map[string]string
-	url             map[string]string
-	headers         map[string]map[string]string
-	queryParameters map[string]map[string]string
+	body            conditionDTO
+	bodyJSONPath    map[string]conditionDTO
+	bodyXPath       map[string]conditionDTO
+	url             conditionDTO
+	headers         map[string]conditionDTO
+	queryParameters map[string]conditionDTO
+	cookies         map[string]conditionDTO
+	pathParameters  map[string]conditionDTO
 	priority        int
 }
 
 type responseBuilder struct {
-	status  int
-	body    []byte
-	headers map[string]string
+	status              int
+	body                []byte
+	headers             map[string]string
+	fixedDelay          time.Duration
+	delayDistribution   *delayDistributionDTO
+	chunkedDribbleDelay *chunkedDribbleDelayDTO
+	fault               Fault
+	template            bool
 }
 
 func Request() RequestBuilder {
@@ -162,25 +445,65 @@ type RequestBuilder interface {
 	URLEqualsTo(value string) RequestBuilder
 	URLContains(value string) RequestBuilder
 	URLPattern(value string) RequestBuilder
+	URLMatching(condition conditionDTO) RequestBuilder
+	URLTemplate(template string) RequestBuilder
+	PathParamIsEqualTo(name string, value string) RequestBuilder
+	PathParamPatternIs(name string, value string) RequestBuilder
+	PathParamMatching(name string, condition conditionDTO) RequestBuilder
 	Method(value string) RequestBuilder
 	WithPriority(value int) RequestBuilder
 	HeaderIsEqualTo(field string, value string) RequestBuilder
 	HeaderContains(field string, value string) RequestBuilder
 	HeaderPatternIs(field string, value string) RequestBuilder
+	HeaderIsEqualToIgnoringCase(field string, value string) RequestBuilder
+	HeaderContainsIgnoringCase(field string, value string) RequestBuilder
+	HeaderMatching(field string, condition conditionDTO) RequestBuilder
+	HeaderHasItem(field string, value string) RequestBuilder
+	HeaderIncludesAll(field string, values ...string) RequestBuilder
+	HeaderExactList(field string, values ...string) RequestBuilder
+	HeaderAbsent(field string) RequestBuilder
+	HeaderPresent(field string) RequestBuilder
 	ParamIsEqualTo(field string, value string) RequestBuilder
 	ParamContains(field string, value string) RequestBuilder
 	ParamPatternIs(field string, value string) RequestBuilder
+	ParamMatching(field string, condition conditionDTO) RequestBuilder
+	ParamHasItem(field string, value string) RequestBuilder
+	ParamIncludesAll(field string, values ...string) RequestBuilder
+	ParamExactList(field string, values ...string) RequestBuilder
+	ParamAbsent(field string) RequestBuilder
+	ParamPresent(field string) RequestBuilder
+	CookieIsEqualTo(name string, value string) RequestBuilder
+	CookieContains(name string, value string) RequestBuilder
+	CookiePatternIs(name string, value string) RequestBuilder
+	CookieMatching(name string, condition conditionDTO) RequestBuilder
+	CookieAbsent(name string) RequestBuilder
+	CookiePresent(name string) RequestBuilder
 	BodyEqualsTo(body string) RequestBuilder
 	BodyContains(part string) RequestBuilder
 	BodyPatternIs(pattern string) RequestBuilder
+	BodyMatching(condition conditionDTO) RequestBuilder
+	BodyEqualsToJSON(body string) RequestBuilder
+	BodyIgnoringExtraFields() RequestBuilder
+	BodyIgnoringArrayOrder() RequestBuilder
+	BodyJSONPathExists(expression string) RequestBuilder
+	BodyJSONPathEqualTo(expression string, value string) RequestBuilder
+	BodyJSONPathContains(expression string, value string) RequestBuilder
+	BodyJSONPathPatternIs(expression string, pattern string) RequestBuilder
+	BodyJSONPathMatching(expression string, condition conditionDTO) RequestBuilder
+	BodyEqualsToXML(body string) RequestBuilder
+	BodyXPathExists(expression string) RequestBuilder
+	BodyXPathEqualTo(expression string, value string) RequestBuilder
+	BodyXPathContains(expression string, value string) RequestBuilder
+	BodyXPathPatternIs(expression string, pattern string) RequestBuilder
+	BodyXPathMatching(expression string, condition conditionDTO) RequestBuilder
 	Build() *requestDTO
 }
 
 func (req *requestBuilder) addUrlEntry(key string, value string) RequestBuilder {
-	if req.url == nil {
-		req.url = map[string]string{}
-	}
-	req.url[key] = value
+	return req.URLMatching(conditionDTO{key: value})
+}
+func (req *requestBuilder) URLMatching(condition conditionDTO) RequestBuilder {
+	req.url = mergeCondition(req.url, condition)
 	return req
 }
 func (req *requestBuilder) URLEqualsTo(value string) RequestBuilder {
@@ -192,6 +515,22 @@ func (req *requestBuilder) URLContains(value string) RequestBuilder {
 func (req *requestBuilder) URLPattern(value string) RequestBuilder {
 	return req.addUrlEntry(operatorPattern, value)
 }
+func (req *requestBuilder) URLTemplate(template string) RequestBuilder {
+	return req.addUrlEntry(operatorURLTemplate, template)
+}
+func (req *requestBuilder) PathParamMatching(name string, condition conditionDTO) RequestBuilder {
+	if req.pathParameters == nil {
+		req.pathParameters = map[string]conditionDTO{}
+	}
+	req.pathParameters[name] = mergeCondition(req.pathParameters[name], condition)
+	return req
+}
+func (req *requestBuilder) PathParamIsEqualTo(name string, value string) RequestBuilder {
+	return req.PathParamMatching(name, EqualTo(value))
+}
+func (req *requestBuilder) PathParamPatternIs(name string, value string) RequestBuilder {
+	return req.PathParamMatching(name, PatternIs(value))
+}
 func (req *requestBuilder) Method(value string) RequestBuilder {
 	req.method = &value
 	return req
@@ -201,10 +540,13 @@ func (req *requestBuilder) WithPriority(value int) RequestBuilder {
 	return req
 }
 func (req *requestBuilder) addHeader(field string, key string, value string) RequestBuilder {
+	return req.HeaderMatching(field, conditionDTO{key: value})
+}
+func (req *requestBuilder) HeaderMatching(field string, condition conditionDTO) RequestBuilder {
 	if req.headers == nil {
-		req.headers = map[string]map[string]string{}
+		req.headers = map[string]conditionDTO{}
 	}
-	req.headers[field] = map[string]string{key: value}
+	req.headers[field] = mergeCondition(req.headers[field], condition)
 	return req
 }
 func (req *requestBuilder) HeaderIsEqualTo(field string, value string) RequestBuilder {
@@ -216,12 +558,36 @@ func (req *requestBuilder) HeaderContains(field string, value string) RequestBui
 func (req *requestBuilder) HeaderPatternIs(field string, value string) RequestBuilder {
 	return req.addHeader(field, operatorPattern, value)
 }
+func (req *requestBuilder) HeaderIsEqualToIgnoringCase(field string, value string) RequestBuilder {
+	return req.addHeader(field, operatorEqualIgnoreCase, value)
+}
+func (req *requestBuilder) HeaderContainsIgnoringCase(field string, value string) RequestBuilder {
+	return req.addHeader(field, operatorContainsIgnoreCase, value)
+}
+func (req *requestBuilder) HeaderHasItem(field string, value string) RequestBuilder {
+	return req.HeaderMatching(field, HasItem(value))
+}
+func (req *requestBuilder) HeaderIncludesAll(field string, values ...string) RequestBuilder {
+	return req.HeaderMatching(field, IncludesAll(values...))
+}
+func (req *requestBuilder) HeaderExactList(field string, values ...string) RequestBuilder {
+	return req.HeaderMatching(field, ExactList(values...))
+}
+func (req *requestBuilder) HeaderAbsent(field string) RequestBuilder {
+	return req.HeaderMatching(field, Absent())
+}
+func (req *requestBuilder) HeaderPresent(field string) RequestBuilder {
+	return req.HeaderMatching(field, Present())
+}
 
 func (req *requestBuilder) addParam(field string, key string, value string) RequestBuilder {
+	return req.ParamMatching(field, conditionDTO{key: value})
+}
+func (req *requestBuilder) ParamMatching(field string, condition conditionDTO) RequestBuilder {
 	if req.queryParameters == nil {
-		req.queryParameters = map[string]map[string]string{}
+		req.queryParameters = map[string]conditionDTO{}
 	}
-	req.queryParameters[field] = map[string]string{key: value}
+	req.queryParameters[field] = mergeCondition(req.queryParameters[field], condition)
 	return req
 }
 
@@ -234,12 +600,50 @@ func (req *requestBuilder) ParamContains(field string, value string) RequestBuil
 func (req *requestBuilder) ParamPatternIs(field string, value string) RequestBuilder {
 	return req.addParam(field, operatorPattern, value)
 }
+func (req *requestBuilder) ParamHasItem(field string, value string) RequestBuilder {
+	return req.ParamMatching(field, HasItem(value))
+}
+func (req *requestBuilder) ParamIncludesAll(field string, values ...string) RequestBuilder {
+	return req.ParamMatching(field, IncludesAll(values...))
+}
+func (req *requestBuilder) ParamExactList(field string, values ...string) RequestBuilder {
+	return req.ParamMatching(field, ExactList(values...))
+}
+func (req *requestBuilder) ParamAbsent(field string) RequestBuilder {
+	return req.ParamMatching(field, Absent())
+}
+func (req *requestBuilder) ParamPresent(field string) RequestBuilder {
+	return req.ParamMatching(field, Present())
+}
 
-func (req *requestBuilder) addBodyMatch(key string, value string) RequestBuilder {
-	if req.body == nil {
-		req.body = map[string]string{}
+func (req *requestBuilder) CookieMatching(name string, condition conditionDTO) RequestBuilder {
+	if req.cookies == nil {
+		req.cookies = map[string]conditionDTO{}
 	}
-	req.body[key] = value
+	req.cookies[name] = mergeCondition(req.cookies[name], condition)
+	return req
+}
+func (req *requestBuilder) CookieIsEqualTo(name string, value string) RequestBuilder {
+	return req.CookieMatching(name, EqualTo(value))
+}
+func (req *requestBuilder) CookieContains(name string, value string) RequestBuilder {
+	return req.CookieMatching(name, Contains(value))
+}
+func (req *requestBuilder) CookiePatternIs(name string, value string) RequestBuilder {
+	return req.CookieMatching(name, PatternIs(value))
+}
+func (req *requestBuilder) CookieAbsent(name string) RequestBuilder {
+	return req.CookieMatching(name, Absent())
+}
+func (req *requestBuilder) CookiePresent(name string) RequestBuilder {
+	return req.CookieMatching(name, Present())
+}
+
+func (req *requestBuilder) addBodyMatch(key string, value string) RequestBuilder {
+	return req.BodyMatching(conditionDTO{key: value})
+}
+func (req *requestBuilder) BodyMatching(condition conditionDTO) RequestBuilder {
+	req.body = mergeCondition(req.body, condition)
 	return req
 }
 
@@ -253,14 +657,80 @@ func (req *requestBuilder) BodyPatternIs(pattern string) RequestBuilder {
 	return req.addBodyMatch(operatorPattern, pattern)
 }
 
+func (req *requestBuilder) BodyEqualsToJSON(body string) RequestBuilder {
+	return req.addBodyMatch(operatorEqualToJSON, body)
+}
+func (req *requestBuilder) BodyIgnoringExtraFields() RequestBuilder {
+	return req.addBodyMatch(optionIgnoreExtraFields, "true")
+}
+func (req *requestBuilder) BodyIgnoringArrayOrder() RequestBuilder {
+	return req.addBodyMatch(optionIgnoreArrayOrder, "true")
+}
+
+func (req *requestBuilder) addBodyJSONPath(expression string, key string, value string) RequestBuilder {
+	return req.BodyJSONPathMatching(expression, conditionDTO{key: value})
+}
+func (req *requestBuilder) BodyJSONPathMatching(expression string, condition conditionDTO) RequestBuilder {
+	if req.bodyJSONPath == nil {
+		req.bodyJSONPath = map[string]conditionDTO{}
+	}
+	req.bodyJSONPath[expression] = mergeCondition(req.bodyJSONPath[expression], condition)
+	return req
+}
+
+func (req *requestBuilder) BodyJSONPathExists(expression string) RequestBuilder {
+	return req.addBodyJSONPath(expression, operatorExists, "")
+}
+func (req *requestBuilder) BodyJSONPathEqualTo(expression string, value string) RequestBuilder {
+	return req.addBodyJSONPath(expression, operatorEqual, value)
+}
+func (req *requestBuilder) BodyJSONPathContains(expression string, value string) RequestBuilder {
+	return req.addBodyJSONPath(expression, operatorContains, value)
+}
+func (req *requestBuilder) BodyJSONPathPatternIs(expression string, pattern string) RequestBuilder {
+	return req.addBodyJSONPath(expression, operatorPattern, pattern)
+}
+
+func (req *requestBuilder) BodyEqualsToXML(body string) RequestBuilder {
+	return req.addBodyMatch(operatorEqualToXML, body)
+}
+
+func (req *requestBuilder) addBodyXPath(expression string, key string, value string) RequestBuilder {
+	return req.BodyXPathMatching(expression, conditionDTO{key: value})
+}
+func (req *requestBuilder) BodyXPathMatching(expression string, condition conditionDTO) RequestBuilder {
+	if req.bodyXPath == nil {
+		req.bodyXPath = map[string]conditionDTO{}
+	}
+	req.bodyXPath[expression] = mergeCondition(req.bodyXPath[expression], condition)
+	return req
+}
+
+func (req *requestBuilder) BodyXPathExists(expression string) RequestBuilder {
+	return req.addBodyXPath(expression, operatorExists, "")
+}
+func (req *requestBuilder) BodyXPathEqualTo(expression string, value string) RequestBuilder {
+	return req.addBodyXPath(expression, operatorEqual, value)
+}
+func (req *requestBuilder) BodyXPathContains(expression string, value string) RequestBuilder {
+	return req.addBodyXPath(expression, operatorContains, value)
+}
+func (req *requestBuilder) BodyXPathPatternIs(expression string, pattern string) RequestBuilder {
+	return req.addBodyXPath(expression, operatorPattern, pattern)
+}
+
 func (req *requestBuilder) Build() *requestDTO {
 	return &requestDTO{
 		URL:             req.url,
 		Method:          req.method,
 		Headers:         req.headers,
 		QueryParameters: req.queryParameters,
+		Cookies:         req.cookies,
+		PathParameters:  req.pathParameters,
 		Priority:        req.priority,
 		Body:            req.body,
+		BodyJSONPath:    req.bodyJSONPath,
+		BodyXPath:       req.bodyXPath,
 	}
 }
 
@@ -270,6 +740,12 @@ type ResponseBuilder interface {
 	WithBodyAsString(value string) ResponseBuilder
 	WithHeader(name string, value string) ResponseBuilder
 	WithHeaders(value map[string]string) ResponseBuilder
+	WithFixedDelay(value time.Duration) ResponseBuilder
+	WithUniformDelay(lower time.Duration, upper time.Duration) ResponseBuilder
+	WithLognormalDelay(median time.Duration, sigma float64) ResponseBuilder
+	WithChunkedDribbleDelay(chunks int, total time.Duration) ResponseBuilder
+	WithFault(fault Fault) ResponseBuilder
+	WithTemplate() ResponseBuilder
 	Build() *responseDTO
 }
 
@@ -296,19 +772,52 @@ func (res *responseBuilder) WithHeaders(value map[string]string) ResponseBuilder
 	res.headers = value
 	return res
 }
-func (res *responseBuilder) Build() *responseDTO {
-	return &responseDTO{
-		Status:  res.status,
-		Body:    res.body,
-		Headers: res.headers,
+func (res *responseBuilder) WithFixedDelay(value time.Duration) ResponseBuilder {
+	res.fixedDelay = value
+	return res
+}
+func (res *responseBuilder) WithUniformDelay(lower time.Duration, upper time.Duration) ResponseBuilder {
+	res.delayDistribution = &delayDistributionDTO{
+		Type:  delayUniform,
+		Lower: lower.Milliseconds(),
+		Upper: upper.Milliseconds(),
 	}
+	return res
 }
-
-func getFirstMapEntry(m map[string]string) (*string, *string) {
-	for key, value := range m {
-		return &key, &value
+func (res *responseBuilder) WithLognormalDelay(median time.Duration, sigma float64) ResponseBuilder {
+	res.delayDistribution = &delayDistributionDTO{
+		Type:   delayLognormal,
+		Median: median.Milliseconds(),
+		Sigma:  sigma,
+	}
+	return res
+}
+func (res *responseBuilder) WithChunkedDribbleDelay(chunks int, total time.Duration) ResponseBuilder {
+	res.chunkedDribbleDelay = &chunkedDribbleDelayDTO{
+		NumberOfChunks: chunks,
+		TotalDuration:  total.Milliseconds(),
+	}
+	return res
+}
+func (res *responseBuilder) WithFault(fault Fault) ResponseBuilder {
+	res.fault = fault
+	return res
+}
+func (res *responseBuilder) WithTemplate() ResponseBuilder {
+	res.template = true
+	return res
+}
+func (res *responseBuilder) Build() *responseDTO {
+	return &responseDTO{
+		Status:              res.status,
+		Body:                res.body,
+		Headers:             res.headers,
+		FixedDelay:          res.fixedDelay.Milliseconds(),
+		DelayDistribution:   res.delayDistribution,
+		ChunkedDribbleDelay: res.chunkedDribbleDelay,
+		Fault:               string(res.fault),
+		Template:            res.template,
 	}
-	return nil, nil
 }
 
 func fromString(name string) operator {
@@ -319,6 +828,34 @@ func fromString(name string) operator {
 		return contains
 	case "pattern":
 		return pattern
+	case "exists":
+		return exists
+	case "equal_to_json":
+		return equalToJSON
+	case "equal_to_xml":
+		return equalToXML
+	case "and":
+		return and
+	case "or":
+		return or
+	case "not":
+		return not
+	case "absent":
+		return absent
+	case "present":
+		return present
+	case "equal_to_ignore_case":
+		return equalIgnoreCase
+	case "contains_ignore_case":
+		return containsIgnoreCase
+	case "has_item":
+		return hasItem
+	case "includes_all":
+		return includesAll
+	case "exact_list":
+		return exactList
+	case "url_template":
+		return urlTemplateMatch
 	default:
 		return undefined
 	}
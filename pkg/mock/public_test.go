@@ -2,6 +2,7 @@ package mock
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -16,17 +17,17 @@ func TestToAggregate(t *testing.T) {
 	m := mockDTO{
 		ID: id,
 		Request: &requestDTO{
-			URL: map[string]string{
+			URL: conditionDTO{
 				"equal_to": url,
 			},
 			Method: &method,
-			Headers: map[string]map[string]string{
+			Headers: map[string]conditionDTO{
 				"Content-Type": {"contains": "application/json"},
 			},
-			QueryParameters: map[string]map[string]string{
+			QueryParameters: map[string]conditionDTO{
 				"query": {"pattern": "sql"},
 			},
-			Body:     map[string]string{"contains": "any-body"},
+			Body:     conditionDTO{"contains": "any-body"},
 			Priority: 1,
 		},
 		Response: &responseDTO{
@@ -71,7 +72,7 @@ func TestToAggregateInvalidUrlOperator(t *testing.T) {
 	responseHeaders := map[string]string{"Content-Type": "application/json"}
 	m := mockDTO{
 		Request: &requestDTO{
-			URL: map[string]string{
+			URL: conditionDTO{
 				"equals": url,
 			},
 		},
@@ -93,7 +94,7 @@ func TestToAggregateInvalidHeaderOperator(t *testing.T) {
 	responseHeaders := map[string]string{"Content-Type": "application/json"}
 	m := mockDTO{
 		Request: &requestDTO{
-			Headers: map[string]map[string]string{
+			Headers: map[string]conditionDTO{
 				"Accept-Encoding": {"match": "gzip"},
 			},
 		},
@@ -115,7 +116,7 @@ func TestToAggregateInvalidQueryParamInvalidOperator(t *testing.T) {
 	responseHeaders := map[string]string{"Content-Type": "application/json"}
 	m := mockDTO{
 		Request: &requestDTO{
-			QueryParameters: map[string]map[string]string{
+			QueryParameters: map[string]conditionDTO{
 				"version": {"match": "1.0.0"},
 			},
 		},
@@ -137,7 +138,7 @@ func TestToAggregateInvalidBodyOperator(t *testing.T) {
 	responseHeaders := map[string]string{"Content-Type": "application/json"}
 	m := mockDTO{
 		Request: &requestDTO{
-			Body: map[string]string{"invalid-condition": "any-value"},
+			Body: conditionDTO{"invalid-condition": "any-value"},
 		},
 		Response: &responseDTO{
 			Status:  responseStatus,
@@ -157,7 +158,7 @@ func TestToAggregateQueryParamWithEmptyCondition(t *testing.T) {
 	responseHeaders := map[string]string{"Content-Type": "application/json"}
 	m := mockDTO{
 		Request: &requestDTO{
-			QueryParameters: map[string]map[string]string{
+			QueryParameters: map[string]conditionDTO{
 				"version": nil,
 			},
 		},
@@ -179,7 +180,7 @@ func TestToAggregateHeadersWithEmptyCondition(t *testing.T) {
 	responseHeaders := map[string]string{"Content-Type": "application/json"}
 	m := mockDTO{
 		Request: &requestDTO{
-			Headers: map[string]map[string]string{
+			Headers: map[string]conditionDTO{
 				"Accept-Version": nil,
 			},
 		},
@@ -242,10 +243,10 @@ func TestRequestBuilderWithContainsCondition(t *testing.T) {
 		Build()
 	assert.Equal(t, method, *req.Method)
 	assert.Equal(t, priority, req.Priority)
-	assert.Equal(t, map[string]string{"contains": url}, req.URL)
-	assert.Equal(t, map[string]map[string]string{"Accept-Encoding": {"contains": "gzip"}, "Content-Type": {"contains": "application/json"}}, req.Headers)
-	assert.Equal(t, map[string]map[string]string{"version": {"contains": "0.1"}, "type": {"contains": "any-type"}}, req.QueryParameters)
-	assert.Equal(t, map[string]string{"contains": "body-part"}, req.Body)
+	assert.Equal(t, conditionDTO{"contains": url}, req.URL)
+	assert.Equal(t, map[string]conditionDTO{"Accept-Encoding": {"contains": "gzip"}, "Content-Type": {"contains": "application/json"}}, req.Headers)
+	assert.Equal(t, map[string]conditionDTO{"version": {"contains": "0.1"}, "type": {"contains": "any-type"}}, req.QueryParameters)
+	assert.Equal(t, conditionDTO{"contains": "body-part"}, req.Body)
 }
 
 func TestRequestBuilderWithEqualsCondition(t *testing.T) {
@@ -264,11 +265,11 @@ func TestRequestBuilderWithEqualsCondition(t *testing.T) {
 		Build()
 	assert.Equal(t, method, *req.Method)
 	assert.Equal(t, priority, req.Priority)
-	assert.Equal(t, map[string]string{"equal_to": url}, req.URL)
-	assert.Equal(t, map[string]map[string]string{"Accept-Encoding": {"equal_to": "gzip"}, "Content-Type": {"equal_to": "application/json"}}, req.Headers)
-	assert.Equal(t, map[string]map[string]string{"version": {"equal_to": "0.1"}, "type": {"equal_to": "any-type"}}, req.QueryParameters)
+	assert.Equal(t, conditionDTO{"equal_to": url}, req.URL)
+	assert.Equal(t, map[string]conditionDTO{"Accept-Encoding": {"equal_to": "gzip"}, "Content-Type": {"equal_to": "application/json"}}, req.Headers)
+	assert.Equal(t, map[string]conditionDTO{"version": {"equal_to": "0.1"}, "type": {"equal_to": "any-type"}}, req.QueryParameters)
 	assert.Equal(t, 1, len(req.Body))
-	assert.Equal(t, map[string]string{"equal_to": "any-condition"}, req.Body)
+	assert.Equal(t, conditionDTO{"equal_to": "any-condition"}, req.Body)
 }
 
 func TestRequestBuilderWithPatternCondition(t *testing.T) {
@@ -287,10 +288,10 @@ func TestRequestBuilderWithPatternCondition(t *testing.T) {
 		Build()
 	assert.Equal(t, method, *req.Method)
 	assert.Equal(t, priority, req.Priority)
-	assert.Equal(t, map[string]string{"pattern": url}, req.URL)
-	assert.Equal(t, map[string]map[string]string{"Accept-Encoding": {"pattern": "gzip"}, "Content-Type": {"pattern": "application/json"}}, req.Headers)
-	assert.Equal(t, map[string]map[string]string{"version": {"pattern": "0.1"}, "type": {"pattern": "any-type"}}, req.QueryParameters)
-	assert.Equal(t, map[string]string{"pattern": "any-pattern"}, req.Body)
+	assert.Equal(t, conditionDTO{"pattern": url}, req.URL)
+	assert.Equal(t, map[string]conditionDTO{"Accept-Encoding": {"pattern": "gzip"}, "Content-Type": {"pattern": "application/json"}}, req.Headers)
+	assert.Equal(t, map[string]conditionDTO{"version": {"pattern": "0.1"}, "type": {"pattern": "any-type"}}, req.QueryParameters)
+	assert.Equal(t, conditionDTO{"pattern": "any-pattern"}, req.Body)
 }
 
 func TestResponseBuilderWithBodyAsString(t *testing.T) {
@@ -323,3 +324,156 @@ func TestResponseBuilderWithBodyAsByteArray(t *testing.T) {
 	assert.Equal(t, status, resp.Status)
 	assert.Equal(t, map[string]string{"Content-Type": "application/json", "Accept": "application/json"}, resp.Headers)
 }
+
+func TestToAggregateKeepsDefinition(t *testing.T) {
+	m := mockDTO{
+		Request: &requestDTO{
+			URL: conditionDTO{"equal_to": "/any-url"},
+		},
+		Response: &responseDTO{Status: 200},
+	}
+	aggregate, err := m.toAggregate()
+	assert.Nil(t, err)
+	assert.Equal(t, aggregate.ID, aggregate.definition.ID)
+	assert.Equal(t, m.Request, aggregate.definition.Request)
+	assert.Equal(t, m.Response, aggregate.definition.Response)
+}
+
+func TestToAggregateWithResponses(t *testing.T) {
+	m := mockDTO{
+		Request: &requestDTO{
+			URL: conditionDTO{"equal_to": "/any-url"},
+		},
+		Responses: []*responseDTO{
+			{Status: 500},
+			{Status: 200, Body: []byte(`{"ok":true}`)},
+		},
+		ResponsesMode: responsesModeLoop,
+	}
+	aggregate, err := m.toAggregate()
+	assert.Nil(t, err)
+	assert.NotNil(t, aggregate.Sequence)
+	assert.True(t, aggregate.Sequence.loop)
+	assert.Equal(t, 500, aggregate.Response.Status)
+	assert.Equal(t, 500, aggregate.nextResponse().Status)
+	assert.Equal(t, 200, aggregate.nextResponse().Status)
+	assert.Equal(t, 500, aggregate.nextResponse().Status)
+}
+
+func TestToAggregateWithNullInResponses(t *testing.T) {
+	m := mockDTO{
+		Request: &requestDTO{
+			URL: conditionDTO{"equal_to": "/any-url"},
+		},
+		Responses: []*responseDTO{{Status: 500}, nil},
+	}
+	_, err := m.toAggregate()
+	assert.Error(t, err)
+	assert.Equal(t, "the mock responses could not contain a null", err.(Error).Cause)
+}
+
+func TestResponseBuilderDelays(t *testing.T) {
+	resp := Response().
+		WithStatus(200).
+		WithFixedDelay(100*time.Millisecond).
+		WithUniformDelay(10*time.Millisecond, 20*time.Millisecond).
+		WithChunkedDribbleDelay(5, time.Second).
+		Build()
+	assert.Equal(t, int64(100), resp.FixedDelay)
+	assert.Equal(t, &delayDistributionDTO{Type: "uniform", Lower: 10, Upper: 20}, resp.DelayDistribution)
+	assert.Equal(t, &chunkedDribbleDelayDTO{NumberOfChunks: 5, TotalDuration: 1000}, resp.ChunkedDribbleDelay)
+	resp = Response().WithLognormalDelay(90*time.Millisecond, 0.1).Build()
+	assert.Equal(t, &delayDistributionDTO{Type: "lognormal", Median: 90, Sigma: 0.1}, resp.DelayDistribution)
+}
+
+func TestToHttpResponseDelays(t *testing.T) {
+	resp, err := (&responseDTO{
+		Status:              200,
+		FixedDelay:          100,
+		DelayDistribution:   &delayDistributionDTO{Type: "lognormal", Median: 90, Sigma: 0.1},
+		ChunkedDribbleDelay: &chunkedDribbleDelayDTO{NumberOfChunks: 5, TotalDuration: 1000},
+	}).toHttpResponse()
+	assert.Nil(t, err)
+	assert.Equal(t, 100*time.Millisecond, resp.FixedDelay)
+	assert.Equal(t, lognormalDelay{median: 90 * time.Millisecond, sigma: 0.1}, resp.RandomDelay)
+	assert.Equal(t, &chunkedDribbleDelay{chunks: 5, total: time.Second}, resp.ChunkedDribble)
+}
+
+func TestToHttpResponseInvalidDelays(t *testing.T) {
+	cases := map[string]responseDTO{
+		"the fixed delay could not be negative": {
+			FixedDelay: -1,
+		},
+		"the uniform delay requires 0 <= lower_ms <= upper_ms": {
+			DelayDistribution: &delayDistributionDTO{Type: "uniform", Lower: 20, Upper: 10},
+		},
+		"the lognormal delay requires a positive median_ms and a non negative sigma": {
+			DelayDistribution: &delayDistributionDTO{Type: "lognormal"},
+		},
+		"the delay distribution gaussian is not supported.": {
+			DelayDistribution: &delayDistributionDTO{Type: "gaussian"},
+		},
+		"the chunked dribble delay requires at least one chunk and a non negative total_duration_ms": {
+			ChunkedDribbleDelay: &chunkedDribbleDelayDTO{},
+		},
+	}
+	for cause, dto := range cases {
+		t.Run(cause, func(t *testing.T) {
+			_, err := dto.toHttpResponse()
+			assert.Error(t, err)
+			assert.Equal(t, cause, err.(Error).Cause)
+		})
+	}
+}
+
+func TestResponseBuilderWithFault(t *testing.T) {
+	resp := Response().WithFault(FaultEmptyResponse).Build()
+	assert.Equal(t, "empty_response", resp.Fault)
+	httpResp, err := resp.toHttpResponse()
+	assert.Nil(t, err)
+	assert.Equal(t, FaultEmptyResponse, httpResp.Fault)
+}
+
+func TestToHttpResponseInvalidFault(t *testing.T) {
+	_, err := (&responseDTO{Fault: "timeout"}).toHttpResponse()
+	assert.Error(t, err)
+	assert.Equal(t, "invalid_request", err.(Error).Code)
+}
+
+func TestResponseBuilderWithTemplate(t *testing.T) {
+	resp := Response().WithStatus(200).WithBodyAsString(`{{.Request.URL}}`).WithTemplate().Build()
+	assert.True(t, resp.Template)
+	httpResp, err := resp.toHttpResponse()
+	assert.Nil(t, err)
+	assert.NotNil(t, httpResp.Template)
+}
+
+func TestToHttpResponseInvalidTemplate(t *testing.T) {
+	_, err := (&responseDTO{Status: 200, Body: []byte(`{{.Request`), Template: true}).toHttpResponse()
+	assert.Error(t, err)
+	assert.Equal(t, "invalid_request", err.(Error).Code)
+}
+
+func TestHeaderNamesAreCanonicalized(t *testing.T) {
+	dto := mockDTO{
+		Request:  Request().HeaderIsEqualTo("content-type", "application/json").HeaderPresent("x-request-id").Build(),
+		Response: Response().WithStatus(200).Build(),
+	}
+	aggregate, err := dto.toAggregate()
+	assert.Nil(t, err)
+	assert.ElementsMatch(t, []string{"Content-Type", "X-Request-Id"},
+		[]string{aggregate.Request.Headers[0].field, aggregate.Request.Headers[1].field})
+	assert.True(t, aggregate.Request.IsExpected(httpRequest{Headers: map[string]string{
+		"Content-Type": "application/json",
+		"X-Request-Id": "1",
+	}}))
+}
+
+func TestIgnoreCaseOperators(t *testing.T) {
+	condition, err := buildCondition(Or(EqualToIgnoringCase("GZIP"), ContainsIgnoringCase("Deflate")))
+	assert.Nil(t, err)
+	assert.True(t, condition.test("gzip"))
+	assert.True(t, condition.test("br, DEFLATE"))
+	assert.False(t, condition.test("br"))
+	assert.Equal(t, `or(equal_to_ignore_case "GZIP", contains_ignore_case "Deflate")`, condition.describe())
+}
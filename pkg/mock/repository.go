@@ -5,25 +5,54 @@ import "sync"
 type Repository interface {
 	Save(info mock) error
 	GetAll() []mock
+	Get(id string) (*mock, error)
+	Delete(id string) error
+	DeleteAll() error
+}
+
+type candidateFinder interface {
+	FindCandidates(request httpRequest) []mock
 }
 
 type inMemoryRepository struct {
-	storage *sync.Map
+	storage  *sync.Map
+	index    *mappingIndex
+	sequence uint64
+	mutex    sync.RWMutex
 }
 
 func newRepository() Repository {
 	return &inMemoryRepository{
 		storage: &sync.Map{},
+		index:   newMappingIndex(),
 	}
 }
 
 func (repo *inMemoryRepository) Save(info mock) error {
 	LogInfo("storing aggregate &v", info)
+	repo.mutex.Lock()
+	repo.sequence++
+	info.sequence = repo.sequence
 	repo.storage.Store(info.ID, info)
+	repo.index.add(info)
+	repo.mutex.Unlock()
 	LogInfo("aggregate stored")
 	return nil
 }
 
+func (repo *inMemoryRepository) FindCandidates(request httpRequest) []mock {
+	repo.mutex.RLock()
+	defer repo.mutex.RUnlock()
+	ids := repo.index.candidates(request)
+	results := make([]mock, 0, len(ids))
+	for id := range ids {
+		if value, exists := repo.storage.Load(id); exists {
+			results = append(results, value.(mock))
+		}
+	}
+	return results
+}
+
 func (repo *inMemoryRepository) GetAll() []mock {
 	LogInfo("getting aggregates")
 	var results []mock
@@ -31,6 +60,39 @@ func (repo *inMemoryRepository) GetAll() []mock {
 		results = append(results, value.(mock))
 		return true
 	})
-	LogInfo("the aggregates &v is returned", results)
+	LogInfo("%d aggregates returned", len(results))
 	return results
 }
+
+func (repo *inMemoryRepository) Get(id string) (*mock, error) {
+	value, exists := repo.storage.Load(id)
+	if !exists {
+		return nil, mappingNotFound(id)
+	}
+	aggregate := value.(mock)
+	return &aggregate, nil
+}
+
+func (repo *inMemoryRepository) Delete(id string) error {
+	repo.mutex.Lock()
+	_, exists := repo.storage.LoadAndDelete(id)
+	repo.index.remove(id)
+	repo.mutex.Unlock()
+	if !exists {
+		return mappingNotFound(id)
+	}
+	LogInfo("aggregate %s deleted", id)
+	return nil
+}
+
+func (repo *inMemoryRepository) DeleteAll() error {
+	repo.mutex.Lock()
+	repo.storage.Range(func(key, _ any) bool {
+		repo.storage.Delete(key)
+		return true
+	})
+	repo.index.clear()
+	repo.mutex.Unlock()
+	LogInfo("all aggregates deleted")
+	return nil
+}
@@ -22,3 +22,55 @@ func TestGetAll(t *testing.T) {
 	assert.NotNil(t, resp)
 	assert.Equal(t, 2, len(resp))
 }
+
+func TestGet(t *testing.T) {
+	repo := newRepository()
+	repo.Save(mock{ID: "1"})
+	resp, err := repo.Get("1")
+	assert.Nil(t, err)
+	assert.Equal(t, "1", resp.ID)
+}
+
+func TestGetNotFound(t *testing.T) {
+	repo := newRepository()
+	_, err := repo.Get("1")
+	assert.Error(t, err)
+	assert.Equal(t, "mapping_not_found", err.(Error).Code)
+}
+
+func TestDelete(t *testing.T) {
+	repo := newRepository()
+	repo.Save(mock{ID: "1"})
+	repo.Save(mock{ID: "2"})
+	err := repo.Delete("1")
+	assert.Nil(t, err)
+	resp := repo.GetAll()
+	assert.Equal(t, 1, len(resp))
+	assert.Equal(t, "2", resp[0].ID)
+}
+
+func TestDeleteNotFound(t *testing.T) {
+	repo := newRepository()
+	err := repo.Delete("1")
+	assert.Error(t, err)
+	assert.Equal(t, "mapping_not_found", err.(Error).Code)
+}
+
+func TestDeleteAll(t *testing.T) {
+	repo := newRepository()
+	repo.Save(mock{ID: "1"})
+	repo.Save(mock{ID: "2"})
+	err := repo.DeleteAll()
+	assert.Nil(t, err)
+	assert.Empty(t, repo.GetAll())
+}
+
+func TestSaveAssignsSequence(t *testing.T) {
+	repo := newRepository()
+	repo.Save(mock{ID: "1"})
+	repo.Save(mock{ID: "2"})
+	repo.Save(mock{ID: "1"})
+	first, _ := repo.Get("1")
+	second, _ := repo.Get("2")
+	assert.Greater(t, first.sequence, second.sequence)
+}
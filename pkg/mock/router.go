@@ -2,65 +2,306 @@ package mock
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 )
 
+const (
+	ephemeralAddress = "127.0.0.1:0"
+	mappingPath      = "/mock/mapping"
+	scenariosPath    = "/mock/scenarios"
+)
+
 type Router interface {
 	Run(string) error
+	Start(string) error
+	Stop(context.Context) error
+	WaitReady(context.Context) error
+	StartEphemeral() (string, error)
+	Address() string
+	BaseURL() string
 }
 
 func newRouter(service Service) *router {
 	r := &router{
 		server:  http.NewServeMux(),
 		service: service,
+		ready:   make(chan struct{}),
 	}
 	r.addMappingRoute()
+	r.addRequestsRoute()
+	r.addScenariosRoute()
 	r.serveMockRoute()
 	return r
 }
 
 type router struct {
-	server  *http.ServeMux
-	service Service
+	server     *http.ServeMux
+	service    Service
+	mutex      sync.Mutex
+	httpServer *http.Server
+	ready      chan struct{}
+	done       chan struct{}
+	serveErr   error
 }
 
 func (r *router) Run(address string) error {
+	err := r.Start(address)
+	if err != nil {
+		return err
+	}
+	r.mutex.Lock()
+	done := r.done
+	r.mutex.Unlock()
+	<-done
+	return r.serveErr
+}
+
+func (r *router) Start(address string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.httpServer != nil {
+		return fmt.Errorf("the server is already started")
+	}
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
 	server := &http.Server{
-		Addr:         address,
+		Addr:         listener.Addr().String(),
 		Handler:      r.server,
 		ReadTimeout:  15 * time.Second,
 		WriteTimeout: 15 * time.Second,
 		IdleTimeout:  60 * time.Second,
 	}
-	return server.ListenAndServe()
+	done := make(chan struct{})
+	r.httpServer = server
+	r.done = done
+	r.serveErr = nil
+	go func() {
+		err := server.Serve(listener)
+		if !errors.Is(err, http.ErrServerClosed) {
+			LogError("error serving mock server, error: %v", err)
+			r.serveErr = err
+		}
+		close(done)
+	}()
+	close(r.ready)
+	LogInfo("mock server started on %s", server.Addr)
+	return nil
+}
+
+func (r *router) StartEphemeral() (string, error) {
+	err := r.Start(ephemeralAddress)
+	if err != nil {
+		return "", err
+	}
+	return r.BaseURL(), nil
+}
+
+func (r *router) Address() string {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.httpServer == nil {
+		return ""
+	}
+	return r.httpServer.Addr
+}
+
+func (r *router) BaseURL() string {
+	address := r.Address()
+	if address == "" {
+		return ""
+	}
+	return "http://" + address
+}
+
+func (r *router) Stop(ctx context.Context) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.httpServer == nil {
+		return nil
+	}
+	err := r.httpServer.Shutdown(ctx)
+	if err != nil {
+		err = errors.Join(err, r.httpServer.Close())
+	}
+	<-r.done
+	r.httpServer = nil
+	r.ready = make(chan struct{})
+	LogInfo("mock server stopped")
+	return err
+}
+
+func (r *router) WaitReady(ctx context.Context) error {
+	r.mutex.Lock()
+	ready := r.ready
+	r.mutex.Unlock()
+	select {
+	case <-ready:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
 
 func (r *router) addMappingRoute() {
-	r.server.HandleFunc("/mock/mapping", func(writer http.ResponseWriter, request *http.Request) {
-		if request.Method != http.MethodPost {
+	r.server.HandleFunc(mappingPath, func(writer http.ResponseWriter, request *http.Request) {
+		switch request.Method {
+		case http.MethodGet:
+			writeAsJson(writer, r.service.List(), http.StatusOK)
+		case http.MethodPost:
+			var dto mockDTO
+			err := decodeAsJson(request.Body, &dto)
+			if err != nil {
+				writeErrorAsJson(err, writer)
+				return
+			}
+			resp, err := r.service.Add(dto)
+			if err != nil {
+				writeErrorAsJson(err, writer)
+				return
+			}
+			writeAsJson(writer, resp, http.StatusOK)
+		case http.MethodDelete:
+			err := r.service.DeleteAll()
+			if err != nil {
+				writeErrorAsJson(err, writer)
+				return
+			}
+			writer.WriteHeader(http.StatusNoContent)
+		default:
 			writer.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+	r.server.HandleFunc(mappingPath+"/", func(writer http.ResponseWriter, request *http.Request) {
+		id := strings.TrimPrefix(request.URL.Path, mappingPath+"/")
+		if id == "" || strings.Contains(id, "/") {
+			writeErrorAsJson(mappingNotFound(id), writer)
 			return
 		}
-		var dto mockDTO
-		err := decodeAsJson(request.Body, &dto)
-		if err != nil {
-			writeErrorAsJson(err, writer)
+		switch request.Method {
+		case http.MethodGet:
+			dto, err := r.service.Get(id)
+			if err != nil {
+				writeErrorAsJson(err, writer)
+				return
+			}
+			writeAsJson(writer, dto, http.StatusOK)
+		case http.MethodPut:
+			var dto mockDTO
+			err := decodeAsJson(request.Body, &dto)
+			if err != nil {
+				writeErrorAsJson(err, writer)
+				return
+			}
+			resp, err := r.service.Update(id, dto)
+			if err != nil {
+				writeErrorAsJson(err, writer)
+				return
+			}
+			writeAsJson(writer, resp, http.StatusOK)
+		case http.MethodDelete:
+			err := r.service.Delete(id)
+			if err != nil {
+				writeErrorAsJson(err, writer)
+				return
+			}
+			writer.WriteHeader(http.StatusNoContent)
+		default:
+			writer.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+}
+
+func (r *router) addRequestsRoute() {
+	r.server.HandleFunc("/mock/requests", func(writer http.ResponseWriter, request *http.Request) {
+		switch request.Method {
+		case http.MethodGet:
+			entries := r.service.Requests()
+			if request.URL.Query().Get("unmatched") == "true" {
+				entries = filterUnmatched(entries)
+			}
+			if entries == nil {
+				entries = []JournalEntry{}
+			}
+			writeAsJson(writer, entries, http.StatusOK)
+		case http.MethodDelete:
+			r.service.ClearRequests()
+			writer.WriteHeader(http.StatusNoContent)
+		default:
+			writer.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+}
+
+func (r *router) addScenariosRoute() {
+	r.server.HandleFunc(scenariosPath, func(writer http.ResponseWriter, request *http.Request) {
+		if request.Method != http.MethodGet {
+			writer.WriteHeader(http.StatusMethodNotAllowed)
 			return
 		}
-		resp, err := r.service.Add(dto)
-		if err != nil {
-			writeErrorAsJson(err, writer)
+		writeAsJson(writer, r.service.Scenarios(), http.StatusOK)
+	})
+	r.server.HandleFunc(scenariosPath+"/reset", func(writer http.ResponseWriter, request *http.Request) {
+		if request.Method != http.MethodPost {
+			writer.WriteHeader(http.StatusMethodNotAllowed)
 			return
 		}
-		writeAsJson(writer, resp, http.StatusOK)
-		return
+		r.service.ResetScenarios()
+		writer.WriteHeader(http.StatusNoContent)
+	})
+	r.server.HandleFunc(scenariosPath+"/", func(writer http.ResponseWriter, request *http.Request) {
+		name, found := strings.CutSuffix(strings.TrimPrefix(request.URL.Path, scenariosPath+"/"), "/state")
+		if !found || name == "" || strings.Contains(name, "/") {
+			writeErrorAsJson(scenarioNotFound(name), writer)
+			return
+		}
+		switch request.Method {
+		case http.MethodGet:
+			state, err := r.service.ScenarioState(name)
+			if err != nil {
+				writeErrorAsJson(err, writer)
+				return
+			}
+			writeAsJson(writer, ScenarioState{Name: name, State: state}, http.StatusOK)
+		case http.MethodPut:
+			var dto ScenarioState
+			err := decodeAsJson(request.Body, &dto)
+			if err != nil {
+				writeErrorAsJson(err, writer)
+				return
+			}
+			err = r.service.SetScenarioState(name, dto.State)
+			if err != nil {
+				writeErrorAsJson(err, writer)
+				return
+			}
+			writer.WriteHeader(http.StatusNoContent)
+		default:
+			writer.WriteHeader(http.StatusMethodNotAllowed)
+		}
 	})
 }
 
+func filterUnmatched(entries []JournalEntry) []JournalEntry {
+	var results []JournalEntry
+	for _, entry := range entries {
+		if !entry.Matched {
+			results = append(results, entry)
+		}
+	}
+	return results
+}
+
 func (r *router) serveMockRoute() {
 	r.server.HandleFunc("/", func(writer http.ResponseWriter, httpRequest *http.Request) {
 		request := buildRequest(httpRequest)
@@ -69,7 +310,12 @@ func (r *router) serveMockRoute() {
 			writeErrorAsJson(err, writer)
 			return
 		}
-		writeHttpResponse(writer, resp)
+		if resp.handler != nil {
+			httpRequest.Body = io.NopCloser(bytes.NewReader(request.Body))
+			resp.handler.ServeHTTP(writer, httpRequest)
+			return
+		}
+		writeHttpResponse(httpRequest.Context(), writer, resp)
 		return
 	})
 }
@@ -99,17 +345,49 @@ func writeAsJson(writer http.ResponseWriter, resp any, status int) {
 	}
 }
 
-func writeHttpResponse(writer http.ResponseWriter, response *httpResponse) {
+func writeHttpResponse(ctx context.Context, writer http.ResponseWriter, response *httpResponse) {
+	if !sleep(ctx, response.delay()) {
+		LogInfo("request cancelled while delaying the response")
+		return
+	}
+	if response.Fault != "" {
+		writeFault(writer, response)
+		return
+	}
 	for key, value := range response.Headers {
 		writer.Header().Add(key, value)
 	}
 	writer.WriteHeader(response.Status)
+	if response.ChunkedDribble != nil {
+		writeChunkedDribble(ctx, writer, response)
+		return
+	}
 	_, err := writer.Write(response.Body)
 	if err != nil {
 		LogError("error writing http response, error: %v", err)
 	}
 }
 
+func writeChunkedDribble(ctx context.Context, writer http.ResponseWriter, response *httpResponse) {
+	chunks := response.ChunkedDribble.split(response.Body)
+	interval := response.ChunkedDribble.total / time.Duration(len(chunks))
+	flusher, canFlush := writer.(http.Flusher)
+	for _, chunk := range chunks {
+		if !sleep(ctx, interval) {
+			LogInfo("request cancelled while dribbling the response")
+			return
+		}
+		_, err := writer.Write(chunk)
+		if err != nil {
+			LogError("error writing http response chunk, error: %v", err)
+			return
+		}
+		if canFlush {
+			flusher.Flush()
+		}
+	}
+}
+
 func decodeAsJson(body io.ReadCloser, destination interface{}) error {
 	buffer := new(bytes.Buffer)
 	_, err := buffer.ReadFrom(body)
@@ -134,8 +412,21 @@ func buildRequest(request *http.Request) httpRequest {
 		Method:          request.Method,
 		QueryParameters: flatValues(queryParams),
 		Headers:         flatValues(header),
+		Cookies:         cookieValues(request.Cookies()),
 		Body:            buf.Bytes(),
+		HeaderValues:    header,
+		QueryValues:     queryParams,
+	}
+}
+
+func cookieValues(cookies []*http.Cookie) map[string]string {
+	values := map[string]string{}
+	for _, cookie := range cookies {
+		if _, exists := values[cookie.Name]; !exists {
+			values[cookie.Name] = cookie.Value
+		}
 	}
+	return values
 }
 
 func flatValues(data map[string][]string) map[string]string {
@@ -150,7 +441,7 @@ func getHttpStatusCodeBy(domainCode string) int {
 	switch domainCode {
 	case "invalid_request":
 		return http.StatusBadRequest
-	case "mock_not_found":
+	case "mock_not_found", "mapping_not_found", "scenario_not_found":
 		return http.StatusNotFound
 	default:
 		return http.StatusInternalServerError
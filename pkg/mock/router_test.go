@@ -1,11 +1,15 @@
 package mock
 
 import (
+	"context"
+	"encoding/json"
 	"io"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	mocking "github.com/stretchr/testify/mock"
@@ -20,6 +24,120 @@ func TestRouterRun(t *testing.T) {
 	})
 }
 
+func TestRouterStartAndStop(t *testing.T) {
+	srv := serviceMock{}
+	router := newRouter(&srv)
+	err := router.Start("127.0.0.1:0")
+	assert.Nil(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.Nil(t, router.WaitReady(ctx))
+	response, err := http.Post("http://"+router.httpServer.Addr+"/mock/requests", "application/json", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusMethodNotAllowed, response.StatusCode)
+	assert.Nil(t, router.Stop(ctx))
+	_, err = http.Post("http://"+response.Request.URL.Host+"/mock/requests", "application/json", nil)
+	assert.Error(t, err)
+}
+
+func TestRouterStartTwice(t *testing.T) {
+	srv := serviceMock{}
+	router := newRouter(&srv)
+	assert.Nil(t, router.Start("127.0.0.1:0"))
+	defer router.Stop(context.Background())
+	err := router.Start("127.0.0.1:0")
+	assert.Error(t, err)
+	assert.Equal(t, "the server is already started", err.Error())
+}
+
+func TestRouterRestartAfterStop(t *testing.T) {
+	srv := serviceMock{}
+	router := newRouter(&srv)
+	assert.Nil(t, router.Start("127.0.0.1:0"))
+	assert.Nil(t, router.Stop(context.Background()))
+	assert.Nil(t, router.Start("127.0.0.1:0"))
+	assert.Nil(t, router.Stop(context.Background()))
+}
+
+func TestRouterStopWithoutStart(t *testing.T) {
+	srv := serviceMock{}
+	router := newRouter(&srv)
+	assert.Nil(t, router.Stop(context.Background()))
+}
+
+func TestRouterWaitReadyTimeout(t *testing.T) {
+	srv := serviceMock{}
+	router := newRouter(&srv)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := router.WaitReady(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestRouterRunReturnsWhenStopped(t *testing.T) {
+	srv := serviceMock{}
+	router := newRouter(&srv)
+	result := make(chan error)
+	go func() {
+		result <- router.Run("127.0.0.1:0")
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.Nil(t, router.WaitReady(ctx))
+	assert.Nil(t, router.Stop(ctx))
+	assert.Nil(t, <-result)
+}
+
+func TestRouterStartInvalidAddress(t *testing.T) {
+	srv := serviceMock{}
+	router := newRouter(&srv)
+	assert.Error(t, router.Start("invalid-address"))
+}
+
+func TestRouterStartEphemeral(t *testing.T) {
+	srv := serviceMock{}
+	router := newRouter(&srv)
+	baseURL, err := router.StartEphemeral()
+	assert.Nil(t, err)
+	defer router.Stop(context.Background())
+	assert.NotEqual(t, "http://127.0.0.1:0", baseURL)
+	assert.Equal(t, router.BaseURL(), baseURL)
+	assert.Equal(t, "http://"+router.Address(), baseURL)
+	response, err := http.Post(baseURL+"/mock/requests", "application/json", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusMethodNotAllowed, response.StatusCode)
+}
+
+func TestRouterStartEphemeralIsolated(t *testing.T) {
+	first := newRouter(&serviceMock{})
+	second := newRouter(&serviceMock{})
+	firstURL, err := first.StartEphemeral()
+	assert.Nil(t, err)
+	defer first.Stop(context.Background())
+	secondURL, err := second.StartEphemeral()
+	assert.Nil(t, err)
+	defer second.Stop(context.Background())
+	assert.NotEqual(t, firstURL, secondURL)
+}
+
+func TestRouterStartEphemeralAlreadyStarted(t *testing.T) {
+	srv := serviceMock{}
+	router := newRouter(&srv)
+	_, err := router.StartEphemeral()
+	assert.Nil(t, err)
+	defer router.Stop(context.Background())
+	baseURL, err := router.StartEphemeral()
+	assert.Error(t, err)
+	assert.Empty(t, baseURL)
+}
+
+func TestRouterAddressWhenNotStarted(t *testing.T) {
+	srv := serviceMock{}
+	router := newRouter(&srv)
+	assert.Empty(t, router.Address())
+	assert.Empty(t, router.BaseURL())
+}
+
 func TestMappingEntrypointErrorMethodNotAllowed(t *testing.T) {
 	srv := serviceMock{}
 	router := newRouter(&srv)
@@ -31,7 +149,7 @@ func TestMappingEntrypointErrorMethodNotAllowed(t *testing.T) {
 			Host:   "localhost",
 			Path:   "/mock/mapping",
 		},
-		Method: http.MethodGet,
+		Method: http.MethodPatch,
 	}
 	router.server.ServeHTTP(&response, &request)
 	response.AssertCalled(t, "WriteHeader", http.StatusMethodNotAllowed)
@@ -184,3 +302,334 @@ func TestMappingServeMockOK(t *testing.T) {
 	response.AssertCalled(t, "Write", mocking.Anything)
 	response.AssertExpectations(t)
 }
+
+func TestRequestsEntrypointGet(t *testing.T) {
+	srv := serviceMock{}
+	router := newRouter(&srv)
+	response := httptest.NewRecorder()
+	srv.On("Requests").Return([]JournalEntry{
+		{ID: "1", Request: ReceivedRequest{URL: "/test"}, Matched: true, MappingID: "10"},
+		{ID: "2", Request: ReceivedRequest{URL: "/other"}},
+	})
+	request := httptest.NewRequest(http.MethodGet, "/mock/requests", nil)
+	router.server.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusOK, response.Code)
+	var entries []JournalEntry
+	assert.Nil(t, json.Unmarshal(response.Body.Bytes(), &entries))
+	assert.Equal(t, 2, len(entries))
+	assert.Equal(t, "10", entries[0].MappingID)
+	srv.AssertExpectations(t)
+}
+
+func TestRequestsEntrypointGetUnmatched(t *testing.T) {
+	srv := serviceMock{}
+	router := newRouter(&srv)
+	response := httptest.NewRecorder()
+	srv.On("Requests").Return([]JournalEntry{
+		{ID: "1", Request: ReceivedRequest{URL: "/test"}, Matched: true, MappingID: "10"},
+		{ID: "2", Request: ReceivedRequest{URL: "/other"}},
+	})
+	request := httptest.NewRequest(http.MethodGet, "/mock/requests?unmatched=true", nil)
+	router.server.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusOK, response.Code)
+	var entries []JournalEntry
+	assert.Nil(t, json.Unmarshal(response.Body.Bytes(), &entries))
+	assert.Equal(t, 1, len(entries))
+	assert.Equal(t, "2", entries[0].ID)
+}
+
+func TestRequestsEntrypointGetEmpty(t *testing.T) {
+	srv := serviceMock{}
+	router := newRouter(&srv)
+	response := httptest.NewRecorder()
+	srv.On("Requests").Return(nil)
+	request := httptest.NewRequest(http.MethodGet, "/mock/requests", nil)
+	router.server.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, "[]", response.Body.String())
+}
+
+func TestRequestsEntrypointDelete(t *testing.T) {
+	srv := serviceMock{}
+	router := newRouter(&srv)
+	response := httptest.NewRecorder()
+	srv.On("ClearRequests").Return()
+	request := httptest.NewRequest(http.MethodDelete, "/mock/requests", nil)
+	router.server.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusNoContent, response.Code)
+	srv.AssertExpectations(t)
+}
+
+func TestRequestsEntrypointMethodNotAllowed(t *testing.T) {
+	srv := serviceMock{}
+	router := newRouter(&srv)
+	response := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodPost, "/mock/requests", nil)
+	router.server.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusMethodNotAllowed, response.Code)
+}
+
+func TestMappingEntrypointList(t *testing.T) {
+	srv := serviceMock{}
+	router := newRouter(&srv)
+	response := httptest.NewRecorder()
+	srv.On("List").Return([]mockDTO{{ID: "1"}, {ID: "2"}})
+	request := httptest.NewRequest(http.MethodGet, "/mock/mapping", nil)
+	router.server.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusOK, response.Code)
+	var mappings []mockDTO
+	assert.Nil(t, json.Unmarshal(response.Body.Bytes(), &mappings))
+	assert.Equal(t, 2, len(mappings))
+	srv.AssertExpectations(t)
+}
+
+func TestMappingEntrypointDeleteAll(t *testing.T) {
+	srv := serviceMock{}
+	router := newRouter(&srv)
+	response := httptest.NewRecorder()
+	srv.On("DeleteAll").Return(nil)
+	request := httptest.NewRequest(http.MethodDelete, "/mock/mapping", nil)
+	router.server.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusNoContent, response.Code)
+	srv.AssertExpectations(t)
+}
+
+func TestMappingByIDEntrypointGet(t *testing.T) {
+	srv := serviceMock{}
+	router := newRouter(&srv)
+	response := httptest.NewRecorder()
+	srv.On("Get", "123").Return(&mockDTO{ID: "123"}, nil)
+	request := httptest.NewRequest(http.MethodGet, "/mock/mapping/123", nil)
+	router.server.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusOK, response.Code)
+	var mapping mockDTO
+	assert.Nil(t, json.Unmarshal(response.Body.Bytes(), &mapping))
+	assert.Equal(t, "123", mapping.ID)
+	srv.AssertExpectations(t)
+}
+
+func TestMappingByIDEntrypointGetNotFound(t *testing.T) {
+	srv := serviceMock{}
+	router := newRouter(&srv)
+	response := httptest.NewRecorder()
+	srv.On("Get", "123").Return(nil, mappingNotFound("123"))
+	request := httptest.NewRequest(http.MethodGet, "/mock/mapping/123", nil)
+	router.server.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusNotFound, response.Code)
+}
+
+func TestMappingByIDEntrypointPut(t *testing.T) {
+	srv := serviceMock{}
+	router := newRouter(&srv)
+	response := httptest.NewRecorder()
+	srv.On("Update", "123", mocking.AnythingOfType("mock.mockDTO")).Return(&addMockResponse{ID: "123"}, nil)
+	request := httptest.NewRequest(http.MethodPut, "/mock/mapping/123", strings.NewReader(`{"response":{"status":200}}`))
+	router.server.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, `{"id":"123"}`, response.Body.String())
+	srv.AssertExpectations(t)
+}
+
+func TestMappingByIDEntrypointPutInvalidJson(t *testing.T) {
+	srv := serviceMock{}
+	router := newRouter(&srv)
+	response := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodPut, "/mock/mapping/123", strings.NewReader(`{invalid json}`))
+	router.server.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusInternalServerError, response.Code)
+	srv.AssertNotCalled(t, "Update", mocking.Anything, mocking.Anything)
+}
+
+func TestMappingByIDEntrypointPutError(t *testing.T) {
+	srv := serviceMock{}
+	router := newRouter(&srv)
+	response := httptest.NewRecorder()
+	srv.On("Update", "123", mocking.AnythingOfType("mock.mockDTO")).Return(nil, invalidRequest("any cause"))
+	request := httptest.NewRequest(http.MethodPut, "/mock/mapping/123", strings.NewReader(`{}`))
+	router.server.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+}
+
+func TestMappingByIDEntrypointDelete(t *testing.T) {
+	srv := serviceMock{}
+	router := newRouter(&srv)
+	response := httptest.NewRecorder()
+	srv.On("Delete", "123").Return(nil)
+	request := httptest.NewRequest(http.MethodDelete, "/mock/mapping/123", nil)
+	router.server.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusNoContent, response.Code)
+	srv.AssertExpectations(t)
+}
+
+func TestMappingByIDEntrypointDeleteNotFound(t *testing.T) {
+	srv := serviceMock{}
+	router := newRouter(&srv)
+	response := httptest.NewRecorder()
+	srv.On("Delete", "123").Return(mappingNotFound("123"))
+	request := httptest.NewRequest(http.MethodDelete, "/mock/mapping/123", nil)
+	router.server.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusNotFound, response.Code)
+}
+
+func TestMappingByIDEntrypointMethodNotAllowed(t *testing.T) {
+	srv := serviceMock{}
+	router := newRouter(&srv)
+	response := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodPost, "/mock/mapping/123", nil)
+	router.server.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusMethodNotAllowed, response.Code)
+}
+
+func TestMappingByIDEntrypointInvalidID(t *testing.T) {
+	srv := serviceMock{}
+	router := newRouter(&srv)
+	response := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodGet, "/mock/mapping/123/other", nil)
+	router.server.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusNotFound, response.Code)
+	srv.AssertNotCalled(t, "Get", mocking.Anything)
+}
+
+func TestScenariosEntrypointList(t *testing.T) {
+	srv := serviceMock{}
+	router := newRouter(&srv)
+	response := httptest.NewRecorder()
+	srv.On("Scenarios").Return([]ScenarioState{{Name: "order", State: "paid"}})
+	request := httptest.NewRequest(http.MethodGet, "/mock/scenarios", nil)
+	router.server.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, `[{"name":"order","state":"paid"}]`, response.Body.String())
+}
+
+func TestScenariosEntrypointListMethodNotAllowed(t *testing.T) {
+	srv := serviceMock{}
+	router := newRouter(&srv)
+	response := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodPost, "/mock/scenarios", nil)
+	router.server.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusMethodNotAllowed, response.Code)
+}
+
+func TestScenariosEntrypointReset(t *testing.T) {
+	srv := serviceMock{}
+	router := newRouter(&srv)
+	response := httptest.NewRecorder()
+	srv.On("ResetScenarios").Return()
+	request := httptest.NewRequest(http.MethodPost, "/mock/scenarios/reset", nil)
+	router.server.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusNoContent, response.Code)
+	srv.AssertExpectations(t)
+}
+
+func TestScenariosEntrypointResetMethodNotAllowed(t *testing.T) {
+	srv := serviceMock{}
+	router := newRouter(&srv)
+	response := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodGet, "/mock/scenarios/reset", nil)
+	router.server.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusMethodNotAllowed, response.Code)
+}
+
+func TestScenarioStateEntrypointGet(t *testing.T) {
+	srv := serviceMock{}
+	router := newRouter(&srv)
+	response := httptest.NewRecorder()
+	srv.On("ScenarioState", "order").Return("paid", nil)
+	request := httptest.NewRequest(http.MethodGet, "/mock/scenarios/order/state", nil)
+	router.server.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, `{"name":"order","state":"paid"}`, response.Body.String())
+}
+
+func TestScenarioStateEntrypointGetNotFound(t *testing.T) {
+	srv := serviceMock{}
+	router := newRouter(&srv)
+	response := httptest.NewRecorder()
+	srv.On("ScenarioState", "order").Return("", scenarioNotFound("order"))
+	request := httptest.NewRequest(http.MethodGet, "/mock/scenarios/order/state", nil)
+	router.server.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusNotFound, response.Code)
+}
+
+func TestScenarioStateEntrypointPut(t *testing.T) {
+	srv := serviceMock{}
+	router := newRouter(&srv)
+	response := httptest.NewRecorder()
+	srv.On("SetScenarioState", "order", "paid").Return(nil)
+	request := httptest.NewRequest(http.MethodPut, "/mock/scenarios/order/state", strings.NewReader(`{"state":"paid"}`))
+	router.server.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusNoContent, response.Code)
+	srv.AssertExpectations(t)
+}
+
+func TestScenarioStateEntrypointPutInvalid(t *testing.T) {
+	srv := serviceMock{}
+	router := newRouter(&srv)
+	response := httptest.NewRecorder()
+	srv.On("SetScenarioState", "order", "").Return(invalidRequest("the scenario state is required"))
+	request := httptest.NewRequest(http.MethodPut, "/mock/scenarios/order/state", strings.NewReader(`{}`))
+	router.server.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+}
+
+func TestScenarioStateEntrypointInvalidPath(t *testing.T) {
+	srv := serviceMock{}
+	router := newRouter(&srv)
+	response := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodGet, "/mock/scenarios/order", nil)
+	router.server.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusNotFound, response.Code)
+	srv.AssertNotCalled(t, "ScenarioState", mocking.Anything)
+}
+
+func TestScenarioStateEntrypointMethodNotAllowed(t *testing.T) {
+	srv := serviceMock{}
+	router := newRouter(&srv)
+	response := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodDelete, "/mock/scenarios/order/state", nil)
+	router.server.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusMethodNotAllowed, response.Code)
+}
+
+func TestServeMockWithFixedDelay(t *testing.T) {
+	srv := serviceMock{}
+	router := newRouter(&srv)
+	srv.On("Match", mocking.AnythingOfType("mock.httpRequest")).Return(&httpResponse{Status: 200, FixedDelay: 30 * time.Millisecond}, nil)
+	response := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodGet, "/test-url", nil)
+	start := time.Now()
+	router.server.ServeHTTP(response, request)
+	assert.GreaterOrEqual(t, time.Since(start), 30*time.Millisecond)
+	assert.Equal(t, http.StatusOK, response.Code)
+}
+
+func TestServeMockDelayCancelled(t *testing.T) {
+	srv := serviceMock{}
+	router := newRouter(&srv)
+	srv.On("Match", mocking.AnythingOfType("mock.httpRequest")).Return(&httpResponse{Status: 200, FixedDelay: time.Second}, nil)
+	response := responseWriterMock{}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	request := httptest.NewRequest(http.MethodGet, "/test-url", nil).WithContext(ctx)
+	start := time.Now()
+	router.server.ServeHTTP(&response, request)
+	assert.Less(t, time.Since(start), time.Second)
+	response.AssertNotCalled(t, "WriteHeader", mocking.Anything)
+}
+
+func TestServeMockWithChunkedDribbleDelay(t *testing.T) {
+	srv := serviceMock{}
+	router := newRouter(&srv)
+	srv.On("Match", mocking.AnythingOfType("mock.httpRequest")).Return(&httpResponse{
+		Status:         200,
+		Body:           []byte("0123456789"),
+		ChunkedDribble: &chunkedDribbleDelay{chunks: 5, total: 50 * time.Millisecond},
+	}, nil)
+	response := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodGet, "/test-url", nil)
+	start := time.Now()
+	router.server.ServeHTTP(response, request)
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+	assert.Equal(t, "0123456789", response.Body.String())
+	assert.True(t, response.Flushed)
+}
@@ -0,0 +1,122 @@
+package mock
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+const scenarioStarted = "Started"
+
+type Scenarios interface {
+	Register(name string, mappingID string)
+	Unregister(name string, mappingID string)
+	State(name string) (string, bool)
+	SetState(name string, state string) error
+	GetAll() []ScenarioState
+	Reset()
+	Clear()
+}
+
+type ScenarioState struct {
+	Name  string `json:"name"`
+	State string `json:"state"`
+}
+
+type scenario struct {
+	name          string
+	requiredState string
+	newState      string
+}
+
+type inMemoryScenarios struct {
+	mutex    sync.RWMutex
+	states   map[string]string
+	mappings map[string]map[string]struct{}
+}
+
+func newScenarios() Scenarios {
+	return &inMemoryScenarios{
+		states:   map[string]string{},
+		mappings: map[string]map[string]struct{}{},
+	}
+}
+
+func (s *inMemoryScenarios) Register(name string, mappingID string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if _, exists := s.states[name]; !exists {
+		s.states[name] = scenarioStarted
+		s.mappings[name] = map[string]struct{}{}
+	}
+	s.mappings[name][mappingID] = struct{}{}
+}
+
+func (s *inMemoryScenarios) Unregister(name string, mappingID string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	mappings, exists := s.mappings[name]
+	if !exists {
+		return
+	}
+	delete(mappings, mappingID)
+	if len(mappings) < 1 {
+		delete(s.mappings, name)
+		delete(s.states, name)
+		LogInfo("scenario %s removed, no mapping uses it", name)
+	}
+}
+
+func (s *inMemoryScenarios) State(name string) (string, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	state, exists := s.states[name]
+	return state, exists
+}
+
+func (s *inMemoryScenarios) SetState(name string, state string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if _, exists := s.states[name]; !exists {
+		return scenarioNotFound(name)
+	}
+	s.states[name] = state
+	LogInfo(fmt.Sprintf("scenario %s moved to state %s", name, state))
+	return nil
+}
+
+func (s *inMemoryScenarios) GetAll() []ScenarioState {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	results := []ScenarioState{}
+	for name, state := range s.states {
+		results = append(results, ScenarioState{Name: name, State: state})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Name < results[j].Name
+	})
+	return results
+}
+
+func (s *inMemoryScenarios) Reset() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for name := range s.states {
+		s.states[name] = scenarioStarted
+	}
+}
+
+func (s *inMemoryScenarios) Clear() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.states = map[string]string{}
+	s.mappings = map[string]map[string]struct{}{}
+}
+
+func (sc *scenario) isActive(scenarios Scenarios) bool {
+	if sc == nil || sc.requiredState == "" {
+		return true
+	}
+	state, _ := scenarios.State(sc.name)
+	return state == sc.requiredState
+}
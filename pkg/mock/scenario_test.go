@@ -0,0 +1,93 @@
+package mock
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScenarioRegister(t *testing.T) {
+	scenarios := newScenarios()
+	scenarios.Register("order", "1")
+	state, exists := scenarios.State("order")
+	assert.True(t, exists)
+	assert.Equal(t, scenarioStarted, state)
+}
+
+func TestScenarioRegisterKeepsState(t *testing.T) {
+	scenarios := newScenarios()
+	scenarios.Register("order", "1")
+	assert.Nil(t, scenarios.SetState("order", "paid"))
+	scenarios.Register("order", "1")
+	state, _ := scenarios.State("order")
+	assert.Equal(t, "paid", state)
+}
+
+func TestScenarioUnregister(t *testing.T) {
+	scenarios := newScenarios()
+	scenarios.Register("order", "1")
+	scenarios.Register("order", "2")
+	assert.Nil(t, scenarios.SetState("order", "paid"))
+	scenarios.Unregister("order", "1")
+	state, _ := scenarios.State("order")
+	assert.Equal(t, "paid", state)
+	scenarios.Unregister("order", "2")
+	_, exists := scenarios.State("order")
+	assert.False(t, exists)
+	scenarios.Unregister("unknown", "1")
+	scenarios.Register("order", "3")
+	state, _ = scenarios.State("order")
+	assert.Equal(t, scenarioStarted, state)
+}
+
+func TestScenarioSetStateNotFound(t *testing.T) {
+	scenarios := newScenarios()
+	err := scenarios.SetState("order", "paid")
+	assert.Error(t, err)
+	assert.Equal(t, "scenario_not_found", err.(Error).Code)
+}
+
+func TestScenarioStateNotFound(t *testing.T) {
+	scenarios := newScenarios()
+	_, exists := scenarios.State("order")
+	assert.False(t, exists)
+}
+
+func TestScenarioGetAll(t *testing.T) {
+	scenarios := newScenarios()
+	assert.Equal(t, []ScenarioState{}, scenarios.GetAll())
+	scenarios.Register("second", "1")
+	scenarios.Register("first", "1")
+	assert.Nil(t, scenarios.SetState("second", "done"))
+	assert.Equal(t, []ScenarioState{
+		{Name: "first", State: scenarioStarted},
+		{Name: "second", State: "done"},
+	}, scenarios.GetAll())
+}
+
+func TestScenarioReset(t *testing.T) {
+	scenarios := newScenarios()
+	scenarios.Register("order", "1")
+	assert.Nil(t, scenarios.SetState("order", "paid"))
+	scenarios.Reset()
+	state, _ := scenarios.State("order")
+	assert.Equal(t, scenarioStarted, state)
+}
+
+func TestScenarioClear(t *testing.T) {
+	scenarios := newScenarios()
+	scenarios.Register("order", "1")
+	scenarios.Clear()
+	assert.Empty(t, scenarios.GetAll())
+}
+
+func TestScenarioIsActive(t *testing.T) {
+	scenarios := newScenarios()
+	scenarios.Register("order", "1")
+	var withoutScenario *scenario
+	assert.True(t, withoutScenario.isActive(scenarios))
+	assert.True(t, (&scenario{name: "order"}).isActive(scenarios))
+	assert.True(t, (&scenario{name: "order", requiredState: scenarioStarted}).isActive(scenarios))
+	assert.False(t, (&scenario{name: "order", requiredState: "paid"}).isActive(scenarios))
+	assert.False(t, (&scenario{name: "unknown", requiredState: scenarioStarted}).isActive(scenarios))
+}
@@ -2,7 +2,9 @@ package mock
 
 func New() (Router, Mocker) {
 	repository := newRepository()
-	service := newService(repository)
+	journal := newJournal()
+	scenarios := newScenarios()
+	service := newService(repository, journal, scenarios)
 	mocker := &mocker{
 		service: service,
 	}
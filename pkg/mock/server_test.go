@@ -1,10 +1,18 @@
 package mock
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	mocking "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewServer(t *testing.T) {
@@ -86,3 +94,743 @@ func TestMockRequestWhenRequestBuilderIsNil(t *testing.T) {
 	srvMock.AssertExpectations(t)
 	srvMock.AssertNotCalled(t, "Add")
 }
+
+func TestVerifyTimesSuccess(t *testing.T) {
+	srvMock := serviceMock{}
+	mocker := internalNew(&srvMock)
+	srvMock.On("Count", mocking.AnythingOfType("*mock.requestDTO")).Return(2, nil)
+	err := mocker.Verify(Request().URLEqualsTo("/inventories").Build()).Times(2)
+	assert.Nil(t, err)
+	srvMock.AssertExpectations(t)
+}
+
+func TestVerifyTimesFailure(t *testing.T) {
+	srvMock := serviceMock{}
+	mocker := internalNew(&srvMock)
+	srvMock.On("Count", mocking.AnythingOfType("*mock.requestDTO")).Return(1, nil)
+	err := mocker.Verify(Request().URLEqualsTo("/inventories").Build()).Times(2)
+	assert.Error(t, err)
+	assert.Equal(t, `expected exactly 2 request(s) matching {"url":{"equal_to":"/inventories"}} but received 1`, err.Error())
+	srvMock.AssertExpectations(t)
+}
+
+func TestVerifyAtLeast(t *testing.T) {
+	srvMock := serviceMock{}
+	mocker := internalNew(&srvMock)
+	srvMock.On("Count", mocking.AnythingOfType("*mock.requestDTO")).Return(3, nil)
+	assert.Nil(t, mocker.Verify(Request().Method("GET").Build()).AtLeast(2))
+	assert.Error(t, mocker.Verify(Request().Method("GET").Build()).AtLeast(4))
+}
+
+func TestVerifyNever(t *testing.T) {
+	srvMock := serviceMock{}
+	mocker := internalNew(&srvMock)
+	srvMock.On("Count", mocking.AnythingOfType("*mock.requestDTO")).Return(0, nil).Once()
+	srvMock.On("Count", mocking.AnythingOfType("*mock.requestDTO")).Return(1, nil).Once()
+	assert.Nil(t, mocker.Verify(Request().Method("DELETE").Build()).Never())
+	assert.Error(t, mocker.Verify(Request().Method("DELETE").Build()).Never())
+}
+
+func TestVerifyServiceError(t *testing.T) {
+	srvMock := serviceMock{}
+	mocker := internalNew(&srvMock)
+	srvMock.On("Count", mocking.AnythingOfType("*mock.requestDTO")).Return(0, invalidRequest("invalid"))
+	err := mocker.Verify(Request().URLEqualsTo("/inventories").Build()).Times(1)
+	assert.Error(t, err)
+	assert.Equal(t, "invalid_request", err.(Error).Code)
+}
+
+func TestVerifyWhenRequestBuilderIsNil(t *testing.T) {
+	srvMock := serviceMock{}
+	mocker := internalNew(&srvMock)
+	err := mocker.Verify(nil).Times(1)
+	assert.Error(t, err)
+	assert.Equal(t, "the request builder to verify could not be nil", err.Error())
+	srvMock.AssertNotCalled(t, "Count")
+}
+
+func TestVerifyReceivedRequests(t *testing.T) {
+	router, mocker := New()
+	baseURL, err := router.StartEphemeral()
+	assert.Nil(t, err)
+	defer router.Stop(context.Background())
+	err = mocker.When(Request().URLEqualsTo("/inventories").Method(http.MethodGet).Build()).
+		ThenReturn(Response().WithStatus(http.StatusOK).Build())
+	assert.Nil(t, err)
+	for i := 0; i < 2; i++ {
+		response, err := http.Get(baseURL + "/inventories")
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+	}
+	assert.Nil(t, mocker.Verify(Request().URLEqualsTo("/inventories").Build()).Times(2))
+	assert.Nil(t, mocker.Verify(Request().URLEqualsTo("/inventories").Build()).AtLeast(1))
+	assert.Nil(t, mocker.Verify(Request().URLEqualsTo("/users").Build()).Never())
+}
+
+func TestRequestsJournal(t *testing.T) {
+	router, mocker := New()
+	baseURL, err := router.StartEphemeral()
+	assert.Nil(t, err)
+	defer router.Stop(context.Background())
+	err = mocker.When(Request().URLEqualsTo("/inventories").Build()).
+		ThenReturn(Response().WithStatus(http.StatusOK).Build())
+	assert.Nil(t, err)
+	_, err = http.Get(baseURL + "/inventories?page=2")
+	assert.Nil(t, err)
+	_, err = http.Get(baseURL + "/unknown")
+	assert.Nil(t, err)
+	entries := mocker.Requests()
+	assert.Equal(t, 2, len(entries))
+	assert.True(t, entries[0].Matched)
+	assert.Equal(t, "2", entries[0].Request.QueryParameters["page"])
+	assert.False(t, entries[1].Matched)
+	found, err := mocker.FindRequests(Request().URLEqualsTo("/unknown").Build())
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(found))
+	mocker.ClearRequests()
+	assert.Empty(t, mocker.Requests())
+}
+
+func TestOverlappingMappingsPreferMostRecent(t *testing.T) {
+	router, mocker := New()
+	baseURL, err := router.StartEphemeral()
+	require.Nil(t, err)
+	defer router.Stop(context.Background())
+	for _, status := range []int{http.StatusOK, http.StatusAccepted, http.StatusCreated} {
+		err = mocker.When(Request().URLContains("/inventories").Build()).
+			ThenReturn(Response().WithStatus(status).Build())
+		require.Nil(t, err)
+	}
+	for i := 0; i < 5; i++ {
+		resp, err := http.Get(baseURL + "/inventories")
+		require.Nil(t, err)
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	}
+	err = mocker.When(Request().URLContains("/inventories").Method(http.MethodGet).Build()).
+		ThenReturn(Response().WithStatus(http.StatusNoContent).Build())
+	require.Nil(t, err)
+	err = mocker.When(Request().URLContains("/inventories").Build()).
+		ThenReturn(Response().WithStatus(http.StatusTeapot).Build())
+	require.Nil(t, err)
+	resp, err := http.Get(baseURL + "/inventories")
+	require.Nil(t, err)
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	entries := mocker.Requests()
+	assert.Equal(t, matchRuleMostRecent, entries[0].MatchRule)
+	assert.Equal(t, matchRuleSpecificity, entries[len(entries)-1].MatchRule)
+}
+
+func TestFindRequestsWhenRequestBuilderIsNil(t *testing.T) {
+	srvMock := serviceMock{}
+	mocker := internalNew(&srvMock)
+	_, err := mocker.FindRequests(nil)
+	assert.Error(t, err)
+	assert.Equal(t, "the request builder to find could not be nil", err.Error())
+	srvMock.AssertNotCalled(t, "FindRequests")
+}
+
+func TestMappingAdminOverHttp(t *testing.T) {
+	router, _ := New()
+	baseURL, err := router.StartEphemeral()
+	assert.Nil(t, err)
+	defer router.Stop(context.Background())
+	response, err := http.Post(baseURL+"/mock/mapping", "application/json",
+		strings.NewReader(`{"id":"users","request":{"url":{"equal_to":"/users"}},"response":{"status":200}}`))
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	request, _ := http.NewRequest(http.MethodPut, baseURL+"/mock/mapping/users",
+		strings.NewReader(`{"request":{"url":{"equal_to":"/users"}},"response":{"status":202}}`))
+	response, err = http.DefaultClient.Do(request)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	response, err = http.Get(baseURL + "/users")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusAccepted, response.StatusCode)
+
+	response, err = http.Get(baseURL + "/mock/mapping/users")
+	assert.Nil(t, err)
+	var mapping mockDTO
+	assert.Nil(t, json.NewDecoder(response.Body).Decode(&mapping))
+	assert.Equal(t, 202, mapping.Response.Status)
+
+	request, _ = http.NewRequest(http.MethodDelete, baseURL+"/mock/mapping", nil)
+	response, err = http.DefaultClient.Do(request)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusNoContent, response.StatusCode)
+
+	response, err = http.Get(baseURL + "/users")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusNotFound, response.StatusCode)
+}
+
+func TestMappingWithNullConditionsOverHttp(t *testing.T) {
+	router, _ := New()
+	baseURL, err := router.StartEphemeral()
+	require.Nil(t, err)
+	defer router.Stop(context.Background())
+	response, err := http.Post(baseURL+"/mock/mapping", "application/json",
+		strings.NewReader(`{"request":{"url":{"equal_to":"/a"},"body":null},"response":{"status":200}}`))
+	require.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	response, err = http.Post(baseURL+"/mock/mapping", "application/json",
+		strings.NewReader(`{"request":{"url":null,"method":"DELETE"},"response":{"status":204}}`))
+	require.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	response, err = http.Get(baseURL + "/a")
+	require.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	request, _ := http.NewRequest(http.MethodDelete, baseURL+"/anything", nil)
+	response, err = http.DefaultClient.Do(request)
+	require.Nil(t, err)
+	assert.Equal(t, http.StatusNoContent, response.StatusCode)
+}
+
+type cleanupRecorder struct {
+	cleanups []func()
+}
+
+func (c *cleanupRecorder) Cleanup(f func()) {
+	c.cleanups = append(c.cleanups, f)
+}
+
+func (c *cleanupRecorder) run() {
+	for _, cleanup := range c.cleanups {
+		cleanup()
+	}
+}
+
+func TestRemove(t *testing.T) {
+	srvMock := serviceMock{}
+	mocker := internalNew(&srvMock)
+	srvMock.On("Delete", "123").Return(nil)
+	assert.Nil(t, mocker.Remove("123"))
+	srvMock.AssertExpectations(t)
+}
+
+func TestRemoveNotFound(t *testing.T) {
+	srvMock := serviceMock{}
+	mocker := internalNew(&srvMock)
+	srvMock.On("Delete", "123").Return(mappingNotFound("123"))
+	err := mocker.Remove("123")
+	assert.Error(t, err)
+	assert.Equal(t, "mapping_not_found", err.(Error).Code)
+}
+
+func TestReset(t *testing.T) {
+	srvMock := serviceMock{}
+	mocker := internalNew(&srvMock)
+	srvMock.On("DeleteAll").Return(nil)
+	assert.Nil(t, mocker.Reset())
+	srvMock.AssertExpectations(t)
+}
+
+func TestScopedRemovesCreatedMappingsOnCleanup(t *testing.T) {
+	srvMock := serviceMock{}
+	recorder := &cleanupRecorder{}
+	mocker := internalNew(&srvMock).Scoped(recorder)
+	srvMock.On("Add", mocking.AnythingOfType("mock.mockDTO")).Return(&addMockResponse{ID: "1"}, nil).Once()
+	srvMock.On("Add", mocking.AnythingOfType("mock.mockDTO")).Return(&addMockResponse{ID: "2"}, nil).Once()
+	srvMock.On("Delete", "1").Return(nil)
+	srvMock.On("Delete", "2").Return(mappingNotFound("2"))
+	for i := 0; i < 2; i++ {
+		err := mocker.When(Request().URLEqualsTo("/inventories").Build()).
+			ThenReturn(Response().WithStatus(200).Build())
+		assert.Nil(t, err)
+	}
+	srvMock.AssertNotCalled(t, "Delete", mocking.Anything)
+	assert.Equal(t, 1, len(recorder.cleanups))
+	recorder.run()
+	srvMock.AssertExpectations(t)
+}
+
+func TestScopedResetRemovesOnlyTrackedMappings(t *testing.T) {
+	srvMock := serviceMock{}
+	recorder := &cleanupRecorder{}
+	mocker := internalNew(&srvMock).Scoped(recorder)
+	srvMock.On("Add", mocking.AnythingOfType("mock.mockDTO")).Return(&addMockResponse{ID: "1"}, nil)
+	srvMock.On("Delete", "1").Return(nil).Once()
+	err := mocker.When(Request().URLEqualsTo("/inventories").Build()).
+		ThenReturn(Response().WithStatus(200).Build())
+	require.Nil(t, err)
+	assert.Nil(t, mocker.Reset())
+	recorder.run()
+	srvMock.AssertExpectations(t)
+	srvMock.AssertNotCalled(t, "DeleteAll")
+}
+
+func TestScopedDoesNotTrackFailedMappings(t *testing.T) {
+	srvMock := serviceMock{}
+	recorder := &cleanupRecorder{}
+	mocker := internalNew(&srvMock).Scoped(recorder)
+	srvMock.On("Add", mocking.AnythingOfType("mock.mockDTO")).Return(nil, invalidRequest("invalid"))
+	err := mocker.When(Request().URLEqualsTo("/inventories").Build()).
+		ThenReturn(Response().WithStatus(200).Build())
+	assert.Error(t, err)
+	recorder.run()
+	srvMock.AssertNotCalled(t, "Delete", mocking.Anything)
+}
+
+func TestScopedSubtestsDoNotLeak(t *testing.T) {
+	router, mocker := New()
+	baseURL, err := router.StartEphemeral()
+	assert.Nil(t, err)
+	defer router.Stop(context.Background())
+	err = mocker.When(Request().URLEqualsTo("/shared").Build()).
+		ThenReturn(Response().WithStatus(http.StatusOK).Build())
+	assert.Nil(t, err)
+	t.Run("scoped", func(t *testing.T) {
+		scoped := mocker.Scoped(t)
+		err := scoped.When(Request().URLEqualsTo("/scoped").Build()).
+			ThenReturn(Response().WithStatus(http.StatusOK).Build())
+		assert.Nil(t, err)
+		response, err := http.Get(baseURL + "/scoped")
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+	})
+	response, err := http.Get(baseURL + "/scoped")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusNotFound, response.StatusCode)
+	response, err = http.Get(baseURL + "/shared")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Nil(t, mocker.Reset())
+	response, err = http.Get(baseURL + "/shared")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusNotFound, response.StatusCode)
+}
+
+func TestScopedSubtestsDoNotLeakScenarioState(t *testing.T) {
+	router, mocker := New()
+	baseURL, err := router.StartEphemeral()
+	require.Nil(t, err)
+	defer router.Stop(context.Background())
+	for _, name := range []string{"first", "second"} {
+		t.Run(name, func(t *testing.T) {
+			scoped := mocker.Scoped(t)
+			err := scoped.When(Request().URLEqualsTo("/orders").Method(http.MethodGet).Build()).
+				InScenario("S").
+				WhenScenarioStateIs(scenarioStarted).
+				WillSetStateTo("done").
+				ThenReturn(Response().WithStatus(http.StatusOK).Build())
+			require.Nil(t, err)
+			response, err := http.Get(baseURL + "/orders")
+			require.Nil(t, err)
+			assert.Equal(t, http.StatusOK, response.StatusCode)
+			assert.Equal(t, []ScenarioState{{Name: "S", State: "done"}}, mocker.Scenarios())
+		})
+	}
+	assert.Empty(t, mocker.Scenarios())
+}
+
+func TestScenarioFlow(t *testing.T) {
+	router, mocker := New()
+	baseURL, err := router.StartEphemeral()
+	assert.Nil(t, err)
+	defer router.Stop(context.Background())
+	err = mocker.When(Request().URLEqualsTo("/orders/1").Method(http.MethodGet).Build()).
+		InScenario("order").
+		WhenScenarioStateIs("Started").
+		ThenReturn(Response().WithStatus(http.StatusAccepted).WithBodyAsString(`{"status":"pending"}`).Build())
+	assert.Nil(t, err)
+	err = mocker.When(Request().URLEqualsTo("/orders/1").Method(http.MethodPost).Build()).
+		InScenario("order").
+		WillSetStateTo("done").
+		ThenReturn(Response().WithStatus(http.StatusOK).Build())
+	assert.Nil(t, err)
+	err = mocker.When(Request().URLEqualsTo("/orders/1").Method(http.MethodGet).Build()).
+		InScenario("order").
+		WhenScenarioStateIs("done").
+		ThenReturn(Response().WithStatus(http.StatusOK).WithBodyAsString(`{"status":"done"}`).Build())
+	assert.Nil(t, err)
+
+	response, err := http.Get(baseURL + "/orders/1")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusAccepted, response.StatusCode)
+	response, err = http.Post(baseURL+"/orders/1", "application/json", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	state, err := mocker.ScenarioState("order")
+	assert.Nil(t, err)
+	assert.Equal(t, "done", state)
+	response, err = http.Get(baseURL + "/orders/1")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	mocker.ResetScenarios()
+	assert.Equal(t, []ScenarioState{{Name: "order", State: "Started"}}, mocker.Scenarios())
+	assert.Nil(t, mocker.SetScenarioState("order", "done"))
+	response, err = http.Get(baseURL + "/orders/1")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+}
+
+func TestMockRequestWithResponseSequence(t *testing.T) {
+	router, mocker := New()
+	baseURL, err := router.StartEphemeral()
+	assert.Nil(t, err)
+	defer router.Stop(context.Background())
+	err = mocker.When(Request().URLEqualsTo("/flaky").Build()).ThenReturn(
+		Response().WithStatus(http.StatusServiceUnavailable).Build(),
+		Response().WithStatus(http.StatusServiceUnavailable).Build(),
+		Response().WithStatus(http.StatusOK).Build(),
+	)
+	assert.Nil(t, err)
+	err = mocker.When(Request().URLEqualsTo("/toggle").Build()).Looping().ThenReturn(
+		Response().WithStatus(http.StatusOK).Build(),
+		Response().WithStatus(http.StatusNoContent).Build(),
+	)
+	assert.Nil(t, err)
+	var flaky, toggle []int
+	for i := 0; i < 4; i++ {
+		response, err := http.Get(baseURL + "/flaky")
+		assert.Nil(t, err)
+		flaky = append(flaky, response.StatusCode)
+		response, err = http.Get(baseURL + "/toggle")
+		assert.Nil(t, err)
+		toggle = append(toggle, response.StatusCode)
+	}
+	assert.Equal(t, []int{503, 503, 200, 200}, flaky)
+	assert.Equal(t, []int{200, 204, 200, 204}, toggle)
+}
+
+func TestMockRequestWithResponsesOverHttp(t *testing.T) {
+	router, _ := New()
+	baseURL, err := router.StartEphemeral()
+	assert.Nil(t, err)
+	defer router.Stop(context.Background())
+	response, err := http.Post(baseURL+"/mock/mapping", "application/json", strings.NewReader(
+		`{"request":{"url":{"equal_to":"/flaky"}},"responses":[{"status":500},{"status":200}],"responses_mode":"loop"}`))
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	var statuses []int
+	for i := 0; i < 3; i++ {
+		response, err := http.Get(baseURL + "/flaky")
+		assert.Nil(t, err)
+		statuses = append(statuses, response.StatusCode)
+	}
+	assert.Equal(t, []int{500, 200, 500}, statuses)
+}
+
+func TestMockRequestWithNilResponseInSequence(t *testing.T) {
+	srvMock := serviceMock{}
+	mocker := internalNew(&srvMock)
+	err := mocker.When(Request().URLEqualsTo("/flaky").Build()).
+		ThenReturn(Response().WithStatus(200).Build(), nil)
+	assert.Error(t, err)
+	assert.Equal(t, "the response builder could not be nil", err.Error())
+	srvMock.AssertNotCalled(t, "Add", mocking.Anything)
+}
+
+func TestMockRequestWithDelayTriggersClientTimeout(t *testing.T) {
+	router, mocker := New()
+	baseURL, err := router.StartEphemeral()
+	assert.Nil(t, err)
+	defer router.Stop(context.Background())
+	err = mocker.When(Request().URLEqualsTo("/slow").Build()).
+		ThenReturn(Response().WithStatus(http.StatusOK).WithFixedDelay(500 * time.Millisecond).Build())
+	assert.Nil(t, err)
+	client := http.Client{Timeout: 50 * time.Millisecond}
+	_, err = client.Get(baseURL + "/slow")
+	assert.Error(t, err)
+}
+
+func TestMockRequestWithFault(t *testing.T) {
+	router, mocker := New()
+	baseURL, err := router.StartEphemeral()
+	assert.Nil(t, err)
+	defer router.Stop(context.Background())
+	err = mocker.When(Request().URLEqualsTo("/broken").Build()).
+		ThenReturn(Response().WithFault(FaultConnectionResetByPeer).Build())
+	assert.Nil(t, err)
+	_, err = http.Get(baseURL + "/broken")
+	assert.Error(t, err)
+}
+
+func TestMockRequestWithBodyJSONPath(t *testing.T) {
+	router, mocker := New()
+	baseURL, err := router.StartEphemeral()
+	assert.Nil(t, err)
+	defer router.Stop(context.Background())
+	err = mocker.When(Request().URLEqualsTo("/orders").
+		BodyJSONPathEqualTo("$.customer.id", "42").
+		BodyJSONPathExists("$.items[0].sku").Build()).
+		ThenReturn(Response().WithStatus(http.StatusCreated).Build())
+	assert.Nil(t, err)
+	response, err := http.Post(baseURL+"/orders", "application/json",
+		strings.NewReader(`{"customer":{"id":42},"items":[{"sku":"x-1"}]}`))
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusCreated, response.StatusCode)
+	response, err = http.Post(baseURL+"/orders", "application/json",
+		strings.NewReader(`{"customer":{"id":7},"items":[{"sku":"x-1"}]}`))
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusNotFound, response.StatusCode)
+}
+
+func TestMockRequestWithBodyEqualsToJSON(t *testing.T) {
+	router, mocker := New()
+	baseURL, err := router.StartEphemeral()
+	assert.Nil(t, err)
+	defer router.Stop(context.Background())
+	err = mocker.When(Request().URLEqualsTo("/orders").
+		BodyEqualsToJSON(`{"id":1,"tags":["a","b"]}`).
+		BodyIgnoringExtraFields().
+		BodyIgnoringArrayOrder().Build()).
+		ThenReturn(Response().WithStatus(http.StatusCreated).Build())
+	assert.Nil(t, err)
+	response, err := http.Post(baseURL+"/orders", "application/json",
+		strings.NewReader(`{"tags":["b","a"], "id":1, "extra":true}`))
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusCreated, response.StatusCode)
+	response, err = http.Post(baseURL+"/mock/mapping", "application/json", strings.NewReader(
+		`{"request":{"url":{"equal_to":"/strict"},"body":{"equal_to_json":"{\"a\":1,\"b\":2}"}},"response":{"status":200}}`))
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	response, err = http.Post(baseURL+"/strict", "application/json", strings.NewReader(`{"b":2, "a":1}`))
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	response, err = http.Post(baseURL+"/strict", "application/json", strings.NewReader(`{"b":2, "a":1, "c":3}`))
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusNotFound, response.StatusCode)
+}
+
+func TestMockRequestWithXMLBody(t *testing.T) {
+	router, mocker := New()
+	baseURL, err := router.StartEphemeral()
+	assert.Nil(t, err)
+	defer router.Stop(context.Background())
+	err = mocker.When(Request().URLEqualsTo("/soap").
+		BodyXPathEqualTo("/Envelope/Body/GetUser/id", "42").Build()).
+		ThenReturn(Response().WithStatus(http.StatusOK).Build())
+	assert.Nil(t, err)
+	err = mocker.When(Request().URLEqualsTo("/ping").BodyEqualsToXML(`<ping a="1" b="2"/>`).Build()).
+		ThenReturn(Response().WithStatus(http.StatusNoContent).Build())
+	assert.Nil(t, err)
+	response, err := http.Post(baseURL+"/soap", "text/xml", strings.NewReader(soapEnvelope))
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	response, err = http.Post(baseURL+"/ping", "text/xml", strings.NewReader("<ping b=\"2\" a=\"1\">\n</ping>"))
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusNoContent, response.StatusCode)
+	response, err = http.Post(baseURL+"/ping", "text/xml", strings.NewReader(`<ping a="1"/>`))
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusNotFound, response.StatusCode)
+}
+
+func TestMockRequestWithConditionCombinators(t *testing.T) {
+	router, mocker := New()
+	baseURL, err := router.StartEphemeral()
+	assert.Nil(t, err)
+	defer router.Stop(context.Background())
+	err = mocker.When(Request().
+		URLMatching(Or(EqualTo("/v1/users"), EqualTo("/v2/users"))).
+		HeaderContains("Accept", "json").
+		HeaderMatching("Accept", Not(Contains("xml"))).Build()).
+		ThenReturn(Response().WithStatus(http.StatusOK).Build())
+	assert.Nil(t, err)
+	response, err := http.Post(baseURL+"/mock/mapping", "application/json", strings.NewReader(
+		`{"request":{"url":{"pattern":"^/v[0-9]+/orders"},"query_parameters":{"status":[{"not":{"equal_to":"closed"}},{"or":[{"equal_to":"open"},{"equal_to":"pending"}]}]}},"response":{"status":204}}`))
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	statusFor := func(path string, accept string) int {
+		request, err := http.NewRequest(http.MethodGet, baseURL+path, nil)
+		require.Nil(t, err)
+		request.Header.Set("Accept", accept)
+		response, err := http.DefaultClient.Do(request)
+		require.Nil(t, err)
+		return response.StatusCode
+	}
+	assert.Equal(t, http.StatusOK, statusFor("/v2/users", "application/json"))
+	assert.Equal(t, http.StatusNotFound, statusFor("/v2/users", "application/json+xml"))
+	assert.Equal(t, http.StatusNotFound, statusFor("/v3/users", "application/json"))
+	assert.Equal(t, http.StatusNoContent, statusFor("/v1/orders?status=pending", "*/*"))
+	assert.Equal(t, http.StatusNotFound, statusFor("/v1/orders?status=closed", "*/*"))
+}
+
+func TestMockRequestWithAbsentAndPresentFields(t *testing.T) {
+	router, mocker := New()
+	baseURL, err := router.StartEphemeral()
+	assert.Nil(t, err)
+	defer router.Stop(context.Background())
+	err = mocker.When(Request().URLEqualsTo("/profile").HeaderAbsent("Authorization").CookieAbsent("session").Build()).
+		ThenReturn(Response().WithStatus(http.StatusUnauthorized).Build())
+	assert.Nil(t, err)
+	err = mocker.When(Request().URLEqualsTo("/profile").HeaderPresent("Authorization").Build()).
+		ThenReturn(Response().WithStatus(http.StatusOK).Build())
+	assert.Nil(t, err)
+	response, err := http.Post(baseURL+"/mock/mapping", "application/json", strings.NewReader(
+		`{"request":{"url":{"equal_to":"/profile"},"cookies":{"session":{"equal_to":"abc"}},"headers":{"Authorization":{"absent":true}}},"response":{"status":202}}`))
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	statusFor := func(authorization string, session string) int {
+		request, err := http.NewRequest(http.MethodGet, baseURL+"/profile", nil)
+		require.Nil(t, err)
+		if authorization != "" {
+			request.Header.Set("Authorization", authorization)
+		}
+		if session != "" {
+			request.AddCookie(&http.Cookie{Name: "session", Value: session})
+		}
+		response, err := http.DefaultClient.Do(request)
+		require.Nil(t, err)
+		return response.StatusCode
+	}
+	assert.Equal(t, http.StatusUnauthorized, statusFor("", ""))
+	assert.Equal(t, http.StatusOK, statusFor("Bearer token", ""))
+	assert.Equal(t, http.StatusAccepted, statusFor("", "abc"))
+	assert.Equal(t, http.StatusNotFound, statusFor("", "other"))
+}
+
+func TestMockRequestWithCaseInsensitiveHeaders(t *testing.T) {
+	router, mocker := New()
+	baseURL, err := router.StartEphemeral()
+	assert.Nil(t, err)
+	defer router.Stop(context.Background())
+	err = mocker.When(Request().URLEqualsTo("/users").
+		HeaderIsEqualToIgnoringCase("content-type", "APPLICATION/JSON").Build()).
+		ThenReturn(Response().WithStatus(http.StatusOK).Build())
+	assert.Nil(t, err)
+	response, err := http.Post(baseURL+"/users", "application/json", strings.NewReader(`{}`))
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	response, err = http.Post(baseURL+"/users", "text/plain", strings.NewReader(`{}`))
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusNotFound, response.StatusCode)
+}
+
+func TestMockRequestWithMultiValuedParameters(t *testing.T) {
+	router, mocker := New()
+	baseURL, err := router.StartEphemeral()
+	assert.Nil(t, err)
+	defer router.Stop(context.Background())
+	err = mocker.When(Request().URLEqualsTo("/items").ParamIncludesAll("tag", "b", "a").Build()).
+		ThenReturn(Response().WithStatus(http.StatusOK).Build())
+	assert.Nil(t, err)
+	response, err := http.Post(baseURL+"/mock/mapping", "application/json", strings.NewReader(
+		`{"request":{"url":{"equal_to":"/items"},"query_parameters":{"tag":{"exact_list":["x","y"]}}},"response":{"status":202}}`))
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	response, err = http.Get(baseURL + "/items?tag=a&tag=c&tag=b")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	response, err = http.Get(baseURL + "/items?tag=x&tag=y")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusAccepted, response.StatusCode)
+	response, err = http.Get(baseURL + "/items?tag=y&tag=x")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusNotFound, response.StatusCode)
+}
+
+func TestMockRequestWithURLTemplate(t *testing.T) {
+	router, mocker := New()
+	baseURL, err := router.StartEphemeral()
+	assert.Nil(t, err)
+	defer router.Stop(context.Background())
+	err = mocker.When(Request().URLTemplate("/users/{id}/orders/{orderId}").PathParamPatternIs("id", "^[0-9]+$").Build()).
+		ThenReturn(Response().WithStatus(http.StatusOK).
+			WithBodyAsString(`{"user":"{{.Request.PathParams.id}}","order":"{{.Request.PathParams.orderId}}"}`).
+			WithTemplate().Build())
+	assert.Nil(t, err)
+	response, err := http.Get(baseURL + "/users/12/orders/a-7")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	var body map[string]string
+	assert.Nil(t, json.NewDecoder(response.Body).Decode(&body))
+	assert.Equal(t, map[string]string{"user": "12", "order": "a-7"}, body)
+	response, err = http.Get(baseURL + "/users/abc/orders/a-7")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusNotFound, response.StatusCode)
+}
+
+func TestMockRequestWithInvalidPattern(t *testing.T) {
+	router, mocker := New()
+	baseURL, err := router.StartEphemeral()
+	assert.Nil(t, err)
+	defer router.Stop(context.Background())
+	err = mocker.When(Request().URLPattern("/users/(").Build()).
+		ThenReturn(Response().WithStatus(http.StatusOK).Build())
+	assert.Error(t, err)
+	response, err := http.Post(baseURL+"/mock/mapping", "application/json", strings.NewReader(
+		`{"request":{"url":{"equal_to":"/users"},"headers":{"Accept":{"pattern":"*json"}}},"response":{"status":200}}`))
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+	response, err = http.Get(baseURL + "/users")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusNotFound, response.StatusCode)
+}
+
+func TestMockRequestWithResponder(t *testing.T) {
+	router, mocker := New()
+	baseURL, err := router.StartEphemeral()
+	require.Nil(t, err)
+	defer router.Stop(context.Background())
+	err = mocker.When(Request().URLTemplate("/users/{id}").Method(http.MethodPost).Build()).
+		ThenRespond(func(request IncomingRequest) ResponseBuilder {
+			body := fmt.Sprintf(`{"id":"%s","tags":"%s","body":%s}`, request.PathParameters["id"],
+				strings.Join(request.QueryParameters["tag"], ","), request.Body)
+			return Response().WithStatus(http.StatusCreated).WithBodyAsString(body)
+		})
+	require.Nil(t, err)
+	response, err := http.Post(baseURL+"/users/7?tag=a&tag=b", "application/json", strings.NewReader(`{"name":"any"}`))
+	require.Nil(t, err)
+	assert.Equal(t, http.StatusCreated, response.StatusCode)
+	var body map[string]any
+	assert.Nil(t, json.NewDecoder(response.Body).Decode(&body))
+	assert.Equal(t, map[string]any{"id": "7", "tags": "a,b", "body": map[string]any{"name": "any"}}, body)
+}
+
+func TestMockRequestWithFailingResponder(t *testing.T) {
+	router, mocker := New()
+	baseURL, err := router.StartEphemeral()
+	require.Nil(t, err)
+	defer router.Stop(context.Background())
+	err = mocker.When(Request().URLEqualsTo("/nil").Build()).
+		ThenRespond(func(request IncomingRequest) ResponseBuilder { return nil })
+	require.Nil(t, err)
+	err = mocker.When(Request().URLEqualsTo("/panic").Build()).
+		ThenRespond(func(request IncomingRequest) ResponseBuilder { panic("boom") })
+	require.Nil(t, err)
+	for _, path := range []string{"/nil", "/panic"} {
+		response, err := http.Get(baseURL + path)
+		require.Nil(t, err)
+		assert.Equal(t, http.StatusInternalServerError, response.StatusCode)
+		var body map[string]any
+		assert.Nil(t, json.NewDecoder(response.Body).Decode(&body))
+		assert.Equal(t, callbackErrorCode, body["code"])
+	}
+}
+
+func TestMockRequestWithHandler(t *testing.T) {
+	router, mocker := New()
+	baseURL, err := router.StartEphemeral()
+	require.Nil(t, err)
+	defer router.Stop(context.Background())
+	err = mocker.When(Request().URLEqualsTo("/echo").BodyContains("ping").Build()).
+		ThenHandle(func(writer http.ResponseWriter, request *http.Request) {
+			body, _ := io.ReadAll(request.Body)
+			writer.Header().Set("X-Method", request.Method)
+			writer.WriteHeader(http.StatusAccepted)
+			writer.Write(body)
+		})
+	require.Nil(t, err)
+	response, err := http.Post(baseURL+"/echo", "text/plain", strings.NewReader("ping"))
+	require.Nil(t, err)
+	assert.Equal(t, http.StatusAccepted, response.StatusCode)
+	assert.Equal(t, http.MethodPost, response.Header.Get("X-Method"))
+	body, _ := io.ReadAll(response.Body)
+	assert.Equal(t, "ping", string(body))
+	assert.True(t, mocker.Requests()[0].Matched)
+}
+
+func TestMockRequestWhenResponderIsNil(t *testing.T) {
+	srvMock := serviceMock{}
+	mocker := internalNew(&srvMock)
+	err := mocker.When(Request().URLEqualsTo("/users").Build()).ThenRespond(nil)
+	assert.Equal(t, "the responder could not be nil", err.Error())
+	err = mocker.When(Request().URLEqualsTo("/users").Build()).ThenHandle(nil)
+	assert.Equal(t, "the handler could not be nil", err.Error())
+	err = mocker.When(nil).ThenHandle(func(http.ResponseWriter, *http.Request) {})
+	assert.Equal(t, "the request builder expected could not be nil", err.Error())
+	srvMock.AssertExpectations(t)
+}
@@ -1,21 +1,42 @@
 package mock
 
 import (
+	"fmt"
 	"sort"
+	"sync"
 )
 
 type Service interface {
 	Add(mock mockDTO) (*addMockResponse, error)
 	Match(request httpRequest) (*httpResponse, error)
+	Count(request *requestDTO) (int, error)
+	CountByMapping(id string) int
+	Requests() []JournalEntry
+	FindRequests(request *requestDTO) ([]JournalEntry, error)
+	ClearRequests()
+	List() []mockDTO
+	Get(id string) (*mockDTO, error)
+	Update(id string, mock mockDTO) (*addMockResponse, error)
+	Delete(id string) error
+	DeleteAll() error
+	Scenarios() []ScenarioState
+	ScenarioState(name string) (string, error)
+	SetScenarioState(name string, state string) error
+	ResetScenarios()
 }
 
 type mockService struct {
 	repository Repository
+	journal    Journal
+	scenarios  Scenarios
+	mutex      sync.Mutex
 }
 
-func newService(repository Repository) Service {
+func newService(repository Repository, journal Journal, scenarios Scenarios) Service {
 	return &mockService{
 		repository: repository,
+		journal:    journal,
+		scenarios:  scenarios,
 	}
 }
 
@@ -35,45 +56,253 @@ func (instance *mockService) Add(mock mockDTO) (*addMockResponse, error) {
 		LogInfo("error saving mock into repository")
 		return nil, err
 	}
+	if aggregate.Scenario != nil {
+		instance.scenarios.Register(aggregate.Scenario.name, aggregate.ID)
+	}
 	return &addMockResponse{
 		ID: aggregate.ID,
 	}, nil
 }
-func (instance *mockService) Match(request httpRequest) (*httpResponse, error) {
+func (instance *mockService) List() []mockDTO {
 	aggregates := instance.repository.GetAll()
-	if aggregates == nil || len(aggregates) < 1 {
-		LogInfo("no aggregates found from repository")
-		return nil, mockNotFound(request)
+	sort.SliceStable(aggregates, func(i, j int) bool {
+		return aggregates[i].ID < aggregates[j].ID
+	})
+	results := []mockDTO{}
+	for _, aggregate := range aggregates {
+		results = append(results, aggregate.definition)
+	}
+	return results
+}
+
+func (instance *mockService) Get(id string) (*mockDTO, error) {
+	aggregate, err := instance.repository.Get(id)
+	if err != nil {
+		LogInfo("error getting mock %s from repository", id)
+		return nil, err
+	}
+	return &aggregate.definition, nil
+}
+
+func (instance *mockService) Update(id string, mock mockDTO) (*addMockResponse, error) {
+	previous, err := instance.repository.Get(id)
+	if err != nil {
+		LogInfo("error getting mock %s to update from repository", id)
+		return nil, err
+	}
+	mock.ID = id
+	response, err := instance.Add(mock)
+	if err == nil && previous.Scenario != nil && (mock.Scenario == nil || mock.Scenario.Name != previous.Scenario.name) {
+		instance.scenarios.Unregister(previous.Scenario.name, id)
+	}
+	return response, err
+}
+
+func (instance *mockService) Delete(id string) error {
+	aggregate, err := instance.repository.Get(id)
+	if err != nil {
+		LogInfo("error getting mock %s to delete from repository", id)
+		return err
+	}
+	err = instance.repository.Delete(id)
+	if err != nil {
+		LogInfo("error deleting mock %s from repository", id)
+		return err
+	}
+	if aggregate.Scenario != nil {
+		instance.scenarios.Unregister(aggregate.Scenario.name, id)
+	}
+	return nil
+}
+
+func (instance *mockService) DeleteAll() error {
+	err := instance.repository.DeleteAll()
+	if err != nil {
+		LogInfo("error deleting all mocks from repository")
+		return err
+	}
+	instance.scenarios.Clear()
+	return nil
+}
+
+func (instance *mockService) Scenarios() []ScenarioState {
+	return instance.scenarios.GetAll()
+}
+
+func (instance *mockService) ScenarioState(name string) (string, error) {
+	state, exists := instance.scenarios.State(name)
+	if !exists {
+		return "", scenarioNotFound(name)
+	}
+	return state, nil
+}
+
+func (instance *mockService) SetScenarioState(name string, state string) error {
+	if state == "" {
+		return invalidRequest("the scenario state is required")
+	}
+	return instance.scenarios.SetState(name, state)
+}
+
+func (instance *mockService) ResetScenarios() {
+	instance.scenarios.Reset()
+}
+
+func (instance *mockService) Match(request httpRequest) (*httpResponse, error) {
+	var err error
+	instance.mutex.Lock()
+	candidates, indexed := instance.candidates(request)
+	aggregate, rule := instance.find(request, candidates)
+	if aggregate != nil && aggregate.Scenario != nil && aggregate.Scenario.newState != "" {
+		err = instance.scenarios.SetState(aggregate.Scenario.name, aggregate.Scenario.newState)
+	}
+	instance.mutex.Unlock()
+	if aggregate == nil {
+		err = instance.notFound(request, candidates, indexed)
+	}
+	if err != nil {
+		instance.journal.Record(newJournalEntry(request, "", ""))
+		return nil, err
+	}
+	instance.journal.Record(newJournalEntry(request, aggregate.ID, rule))
+	response := aggregate.nextResponse()
+	if response.callback != nil {
+		request.PathParameters = aggregate.Request.URL.pathParameters(request.URL)
+		return &httpResponse{handler: response.callback.bind(request)}, nil
+	}
+	if response.Template != nil {
+		request.PathParameters = aggregate.Request.URL.pathParameters(request.URL)
+		return response.Template.render(*response, request)
 	}
+	return response, nil
+}
+
+func (instance *mockService) find(request httpRequest, candidates []mock) (*mock, string) {
 	var filteredAggregates []mock
-	for _, aggregate := range aggregates {
-		if aggregate.Request.IsExpected(request) {
+	for _, aggregate := range candidates {
+		if aggregate.Request.IsExpected(request) && aggregate.Scenario.isActive(instance.scenarios) {
 			filteredAggregates = append(filteredAggregates, aggregate)
 		}
 	}
 	if len(filteredAggregates) < 1 {
-		LogInfo("no aggregates found for request %v", request)
-		return nil, mockNotFound(request)
+		return nil, ""
 	}
-	sort.SliceStable(filteredAggregates, func(i, j int) bool {
-		return filteredAggregates[i].Request.Priority > filteredAggregates[j].Request.Priority
-	})
-	LogInfo("filter aggregates are %v", filteredAggregates)
-	return &filteredAggregates[0].Response, nil
+	aggregate, rule := selectMapping(filteredAggregates)
+	LogInfo(fmt.Sprintf("mapping %s selected by %s among %d matching mappings", aggregate.ID, rule, len(filteredAggregates)))
+	return &aggregate, rule
+}
+
+func (instance *mockService) notFound(request httpRequest, candidates []mock, indexed bool) error {
+	aggregates := candidates
+	if indexed {
+		aggregates = instance.repository.GetAll()
+	}
+	if len(aggregates) < 1 {
+		LogInfo("no aggregates found from repository")
+		return mockNotFound(request)
+	}
+	LogInfo("no aggregates found for request %v", request)
+	nearMisses := findNearMisses(aggregates, request, instance.scenarios)
+	for _, nearMiss := range nearMisses {
+		LogInfo("near miss %s", nearMiss.String())
+	}
+	return mockNotFoundWithNearMisses(request, nearMisses)
+}
+
+func (instance *mockService) candidates(request httpRequest) ([]mock, bool) {
+	if finder, indexed := instance.repository.(candidateFinder); indexed {
+		return finder.FindCandidates(request), true
+	}
+	return instance.repository.GetAll(), false
+}
+
+func (instance *mockService) Count(request *requestDTO) (int, error) {
+	if request == nil {
+		return 0, invalidRequest("the request to verify could not be a null")
+	}
+	match, err := toRequestMatch(mockDTO{Request: request})
+	if err != nil {
+		LogInfo("error when convert verification to request match")
+		return 0, err
+	}
+	count := 0
+	for _, entry := range instance.journal.GetAll() {
+		if match.IsExpected(entry.request) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (instance *mockService) CountByMapping(id string) int {
+	count := 0
+	for _, entry := range instance.journal.GetAll() {
+		if entry.mappingID == id {
+			count++
+		}
+	}
+	return count
+}
+
+func (instance *mockService) Requests() []JournalEntry {
+	var results []JournalEntry
+	for _, entry := range instance.journal.GetAll() {
+		results = append(results, entry.toPublic())
+	}
+	return results
+}
+
+func (instance *mockService) FindRequests(request *requestDTO) ([]JournalEntry, error) {
+	if request == nil {
+		return nil, invalidRequest("the request to find could not be a null")
+	}
+	match, err := toRequestMatch(mockDTO{Request: request})
+	if err != nil {
+		LogInfo("error when convert request condition to request match")
+		return nil, err
+	}
+	var results []JournalEntry
+	for _, entry := range instance.journal.GetAll() {
+		if match.IsExpected(entry.request) {
+			results = append(results, entry.toPublic())
+		}
+	}
+	return results, nil
+}
+
+func (instance *mockService) ClearRequests() {
+	instance.journal.Clear()
 }
 
 func validate(m mockDTO) error {
 	if m.Request == nil {
 		return invalidRequest("the mock request could not be a null")
 	}
-	if m.Response == nil {
+	if m.Response == nil && len(m.Responses) < 1 && m.callback == nil {
 		return invalidRequest("the mock response could not be a null")
 	}
-	if m.Request.URL == nil && m.Request.Method == nil && m.Request.Headers == nil && m.Request.QueryParameters == nil {
+	if m.Request.URL == nil && m.Request.Method == nil && m.Request.Headers == nil && m.Request.QueryParameters == nil && m.Request.Cookies == nil && m.Request.Body == nil && m.Request.BodyJSONPath == nil && m.Request.BodyXPath == nil {
 		return invalidRequest("the request has no conditions")
 	}
-	if m.Response.Status == 0 {
+	if m.Response != nil && len(m.Responses) > 0 {
+		return invalidRequest("the mock could not have response and responses at the same time")
+	}
+	if m.Response != nil && m.Response.Status == 0 && m.Response.Fault == "" {
 		return invalidRequest("the response status is required")
 	}
+	for _, response := range m.Responses {
+		if response == nil {
+			return invalidRequest("the mock responses could not contain a null")
+		}
+		if response.Status == 0 && response.Fault == "" {
+			return invalidRequest("the response status is required")
+		}
+	}
+	if m.ResponsesMode != "" && m.ResponsesMode != responsesModeRepeatLast && m.ResponsesMode != responsesModeLoop {
+		return invalidRequest(fmt.Sprintf("the responses mode %s is not supported.", m.ResponsesMode))
+	}
+	if m.Scenario != nil && m.Scenario.Name == "" {
+		return invalidRequest("the scenario name is required")
+	}
 	return nil
 }
@@ -17,14 +17,14 @@ func TestAddSuccess(t *testing.T) {
 	m := mockDTO{
 		ID: id,
 		Request: &requestDTO{
-			URL: map[string]string{
+			URL: conditionDTO{
 				"equal_to": url,
 			},
 			Method: &method,
-			Headers: map[string]map[string]string{
+			Headers: map[string]conditionDTO{
 				"Content-Type": {"contains": "application/json"},
 			},
-			QueryParameters: map[string]map[string]string{
+			QueryParameters: map[string]conditionDTO{
 				"query": {"pattern": "sql"},
 			},
 			Priority: 1,
@@ -37,7 +37,7 @@ func TestAddSuccess(t *testing.T) {
 	}
 	repo := repositoryMock{}
 	repo.On("Save", mocking.AnythingOfType("mock.mock")).Return(nil)
-	service := newService(&repo)
+	service := newService(&repo, newJournal(), newScenarios())
 	res, err := service.Add(m)
 	assert.Nil(t, err)
 	assert.NotNil(t, res)
@@ -55,14 +55,14 @@ func TestAddRepositoryError(t *testing.T) {
 	m := mockDTO{
 		ID: id,
 		Request: &requestDTO{
-			URL: map[string]string{
+			URL: conditionDTO{
 				"equal_to": url,
 			},
 			Method: &method,
-			Headers: map[string]map[string]string{
+			Headers: map[string]conditionDTO{
 				"Content-Type": {"contains": "application/json"},
 			},
-			QueryParameters: map[string]map[string]string{
+			QueryParameters: map[string]conditionDTO{
 				"query": {"pattern": "sql"},
 			},
 			Priority: 1,
@@ -75,7 +75,7 @@ func TestAddRepositoryError(t *testing.T) {
 	}
 	repo := repositoryMock{}
 	repo.On("Save", mocking.AnythingOfType("mock.mock")).Return(invalidRequest("any cause"))
-	service := newService(&repo)
+	service := newService(&repo, newJournal(), newScenarios())
 	_, err := service.Add(m)
 	assert.Error(t, err)
 	repo.AssertExpectations(t)
@@ -90,7 +90,7 @@ func TestAddInvalidRequestInfo(t *testing.T) {
 	m := mockDTO{
 		ID: id,
 		Request: &requestDTO{
-			URL: map[string]string{
+			URL: conditionDTO{
 				"equal": url,
 			},
 			Priority: 1,
@@ -102,7 +102,7 @@ func TestAddInvalidRequestInfo(t *testing.T) {
 		},
 	}
 	repo := repositoryMock{}
-	service := newService(&repo)
+	service := newService(&repo, newJournal(), newScenarios())
 	_, err := service.Add(m)
 	assert.Error(t, err)
 	repo.AssertExpectations(t)
@@ -122,7 +122,7 @@ func TestAddInvalidMockRequest(t *testing.T) {
 		},
 	}
 	repo := repositoryMock{}
-	service := newService(&repo)
+	service := newService(&repo, newJournal(), newScenarios())
 	_, err := service.Add(m)
 	assert.Error(t, err)
 	assert.Equal(t, "the mock request could not be a null", err.(Error).Cause)
@@ -133,14 +133,14 @@ func TestAddInvalidMockRequest(t *testing.T) {
 func TestAddInvalidMockResponse(t *testing.T) {
 	m := mockDTO{
 		Request: &requestDTO{
-			URL: map[string]string{
+			URL: conditionDTO{
 				"equal_to": "/test",
 			},
 		},
 		Response: nil,
 	}
 	repo := repositoryMock{}
-	service := newService(&repo)
+	service := newService(&repo, newJournal(), newScenarios())
 	_, err := service.Add(m)
 	assert.Error(t, err)
 	assert.Equal(t, "the mock response could not be a null", err.(Error).Cause)
@@ -151,14 +151,14 @@ func TestAddInvalidMockResponse(t *testing.T) {
 func TestAddInvalidMockResponseCode(t *testing.T) {
 	m := mockDTO{
 		Request: &requestDTO{
-			URL: map[string]string{
+			URL: conditionDTO{
 				"equal_to": "/test",
 			},
 		},
 		Response: &responseDTO{},
 	}
 	repo := repositoryMock{}
-	service := newService(&repo)
+	service := newService(&repo, newJournal(), newScenarios())
 	_, err := service.Add(m)
 	assert.Error(t, err)
 	assert.Equal(t, "the response status is required", err.(Error).Cause)
@@ -172,7 +172,7 @@ func TestAddInvalidMockRequestData(t *testing.T) {
 		Response: &responseDTO{},
 	}
 	repo := repositoryMock{}
-	service := newService(&repo)
+	service := newService(&repo, newJournal(), newScenarios())
 	_, err := service.Add(m)
 	assert.Error(t, err)
 	assert.Equal(t, "the request has no conditions", err.(Error).Cause)
@@ -180,6 +180,20 @@ func TestAddInvalidMockRequestData(t *testing.T) {
 	repo.AssertNotCalled(t, "Add")
 }
 
+func TestAddWithOnlyBodyCondition(t *testing.T) {
+	for _, body := range []conditionDTO{EqualToXML(`<a/>`), EqualToJSON(`{"a":1}`)} {
+		repo := repositoryMock{}
+		repo.On("Save", mocking.AnythingOfType("mock.mock")).Return(nil)
+		service := newService(&repo, newJournal(), newScenarios())
+		_, err := service.Add(mockDTO{
+			Request:  &requestDTO{Body: body},
+			Response: &responseDTO{Status: 200},
+		})
+		assert.Nil(t, err)
+		repo.AssertExpectations(t)
+	}
+}
+
 func TestMatchSuccess(t *testing.T) {
 	aggregates := []mock{
 		{
@@ -213,7 +227,7 @@ func TestMatchSuccess(t *testing.T) {
 		URL: "/test",
 	}
 	repo := repositoryMock{}
-	service := newService(&repo)
+	service := newService(&repo, newJournal(), newScenarios())
 	repo.On("GetAll").Return(aggregates)
 	resp, err := service.Match(req)
 	assert.Nil(t, err)
@@ -257,7 +271,7 @@ func TestMatchSuccessOrderPriority(t *testing.T) {
 		URL: "/test",
 	}
 	repo := repositoryMock{}
-	service := newService(&repo)
+	service := newService(&repo, newJournal(), newScenarios())
 	repo.On("GetAll").Return(aggregates)
 	resp, err := service.Match(req)
 	assert.Nil(t, err)
@@ -266,6 +280,23 @@ func TestMatchSuccessOrderPriority(t *testing.T) {
 	repo.AssertExpectations(t)
 }
 
+func TestMatchRecordsMatchRuleInJournal(t *testing.T) {
+	aggregates := []mock{
+		{ID: "1", Request: requestMatch{URL: &simplexCondition{operator: equal, value: "/test"}}, sequence: 1},
+		{ID: "2", Request: requestMatch{URL: &simplexCondition{operator: contains, value: "test"}}, sequence: 2},
+	}
+	repo := repositoryMock{}
+	journal := journalMock{}
+	service := newService(&repo, &journal, newScenarios())
+	repo.On("GetAll").Return(aggregates)
+	journal.On("Record", mocking.MatchedBy(func(entry journalEntry) bool {
+		return entry.mappingID == "2" && entry.matchRule == matchRuleMostRecent
+	})).Return()
+	_, err := service.Match(httpRequest{URL: "/test"})
+	assert.Nil(t, err)
+	journal.AssertExpectations(t)
+}
+
 func TestMatchWhenAllAggregatesAllFiltered(t *testing.T) {
 	aggregates := []mock{
 		{
@@ -299,7 +330,7 @@ func TestMatchWhenAllAggregatesAllFiltered(t *testing.T) {
 		URL: "/other",
 	}
 	repo := repositoryMock{}
-	service := newService(&repo)
+	service := newService(&repo, newJournal(), newScenarios())
 	repo.On("GetAll").Return(aggregates)
 	_, err := service.Match(req)
 	assert.Error(t, err)
@@ -313,10 +344,473 @@ func TestMatchNullAggregates(t *testing.T) {
 		URL: "/test",
 	}
 	repo := repositoryMock{}
-	service := newService(&repo)
+	service := newService(&repo, newJournal(), newScenarios())
 	repo.On("GetAll").Return(aggregates)
 	_, err := service.Match(req)
 	assert.Error(t, err)
 	assert.Equal(t, "mock_not_found", err.(Error).Code)
 	repo.AssertExpectations(t)
 }
+
+func TestMatchRecordsRequestInJournal(t *testing.T) {
+	req := httpRequest{
+		URL: "/test",
+	}
+	repo := repositoryMock{}
+	journal := journalMock{}
+	service := newService(&repo, &journal, newScenarios())
+	repo.On("GetAll").Return(nil)
+	journal.On("Record", mocking.MatchedBy(func(entry journalEntry) bool {
+		return entry.request.URL == req.URL && entry.mappingID == ""
+	})).Return()
+	_, err := service.Match(req)
+	assert.Error(t, err)
+	journal.AssertExpectations(t)
+}
+
+func TestCountSuccess(t *testing.T) {
+	method := getMethod
+	repo := repositoryMock{}
+	journal := journalMock{}
+	journal.On("GetAll").Return([]journalEntry{
+		newJournalEntry(httpRequest{URL: "/test", Method: getMethod}, "", ""),
+		newJournalEntry(httpRequest{URL: "/test", Method: postMethod}, "", ""),
+		newJournalEntry(httpRequest{URL: "/test/123", Method: getMethod}, "", ""),
+	})
+	service := newService(&repo, &journal, newScenarios())
+	count, err := service.Count(&requestDTO{
+		URL:    conditionDTO{"contains": "/test"},
+		Method: &method,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, count)
+	journal.AssertExpectations(t)
+}
+
+func TestCountNilRequest(t *testing.T) {
+	repo := repositoryMock{}
+	journal := journalMock{}
+	service := newService(&repo, &journal, newScenarios())
+	_, err := service.Count(nil)
+	assert.Error(t, err)
+	assert.Equal(t, "the request to verify could not be a null", err.(Error).Cause)
+	journal.AssertNotCalled(t, "GetAll")
+}
+
+func TestCountInvalidOperator(t *testing.T) {
+	repo := repositoryMock{}
+	journal := journalMock{}
+	service := newService(&repo, &journal, newScenarios())
+	_, err := service.Count(&requestDTO{
+		URL: conditionDTO{"equals": "/test"},
+	})
+	assert.Error(t, err)
+	assert.Equal(t, "invalid_request", err.(Error).Code)
+	journal.AssertNotCalled(t, "GetAll")
+}
+
+func TestMatchRecordsMatchedMappingInJournal(t *testing.T) {
+	aggregates := []mock{
+		{
+			ID: "1",
+			Request: requestMatch{
+				URL: &simplexCondition{
+					operator: equal,
+					value:    "/test",
+				},
+			},
+			Response: httpResponse{
+				Status: 200,
+			},
+		},
+	}
+	repo := repositoryMock{}
+	journal := newJournal()
+	service := newService(&repo, journal, newScenarios())
+	repo.On("GetAll").Return(aggregates)
+	_, err := service.Match(httpRequest{URL: "/test"})
+	assert.Nil(t, err)
+	_, err = service.Match(httpRequest{URL: "/other"})
+	assert.Error(t, err)
+	entries := service.Requests()
+	assert.Equal(t, 2, len(entries))
+	assert.True(t, entries[0].Matched)
+	assert.Equal(t, "1", entries[0].MappingID)
+	assert.Equal(t, "/test", entries[0].Request.URL)
+	assert.False(t, entries[1].Matched)
+	assert.Equal(t, "/other", entries[1].Request.URL)
+}
+
+func TestFindRequests(t *testing.T) {
+	repo := repositoryMock{}
+	journal := journalMock{}
+	journal.On("GetAll").Return([]journalEntry{
+		newJournalEntry(httpRequest{URL: "/test", Method: getMethod}, "1", ""),
+		newJournalEntry(httpRequest{URL: "/other", Method: getMethod}, "", ""),
+	})
+	service := newService(&repo, &journal, newScenarios())
+	entries, err := service.FindRequests(&requestDTO{
+		URL: conditionDTO{"equal_to": "/other"},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(entries))
+	assert.Equal(t, "/other", entries[0].Request.URL)
+	journal.AssertExpectations(t)
+}
+
+func TestFindRequestsNilRequest(t *testing.T) {
+	repo := repositoryMock{}
+	journal := journalMock{}
+	service := newService(&repo, &journal, newScenarios())
+	_, err := service.FindRequests(nil)
+	assert.Error(t, err)
+	assert.Equal(t, "the request to find could not be a null", err.(Error).Cause)
+	journal.AssertNotCalled(t, "GetAll")
+}
+
+func TestClearRequests(t *testing.T) {
+	repo := repositoryMock{}
+	journal := journalMock{}
+	journal.On("Clear").Return()
+	service := newService(&repo, &journal, newScenarios())
+	service.ClearRequests()
+	journal.AssertExpectations(t)
+}
+
+func TestMatchNotFoundReportsNearMisses(t *testing.T) {
+	method := getMethod
+	aggregates := []mock{
+		{
+			ID: "1",
+			Request: requestMatch{
+				URL:    &simplexCondition{operator: equal, value: "/test"},
+				Method: &method,
+			},
+		},
+	}
+	repo := repositoryMock{}
+	service := newService(&repo, newJournal(), newScenarios())
+	repo.On("GetAll").Return(aggregates)
+	_, err := service.Match(httpRequest{URL: "/test", Method: postMethod})
+	assert.Error(t, err)
+	assert.Equal(t, "mock_not_found", err.(Error).Code)
+	assert.Equal(t, 1, len(err.(Error).NearMisses))
+	assert.Equal(t, "1", err.(Error).NearMisses[0].MappingID)
+	assert.Contains(t, err.Error(), "method POST is not GET")
+}
+
+func TestList(t *testing.T) {
+	repo := repositoryMock{}
+	service := newService(&repo, newJournal(), newScenarios())
+	repo.On("GetAll").Return([]mock{
+		{ID: "2", definition: mockDTO{ID: "2"}},
+		{ID: "1", definition: mockDTO{ID: "1"}},
+	})
+	resp := service.List()
+	assert.Equal(t, []mockDTO{{ID: "1"}, {ID: "2"}}, resp)
+	repo.AssertExpectations(t)
+}
+
+func TestListEmpty(t *testing.T) {
+	repo := repositoryMock{}
+	service := newService(&repo, newJournal(), newScenarios())
+	repo.On("GetAll").Return(nil)
+	resp := service.List()
+	assert.NotNil(t, resp)
+	assert.Empty(t, resp)
+}
+
+func TestGetMapping(t *testing.T) {
+	repo := repositoryMock{}
+	service := newService(&repo, newJournal(), newScenarios())
+	repo.On("Get", "1").Return(&mock{ID: "1", definition: mockDTO{ID: "1"}}, nil)
+	resp, err := service.Get("1")
+	assert.Nil(t, err)
+	assert.Equal(t, "1", resp.ID)
+	repo.AssertExpectations(t)
+}
+
+func TestGetMappingNotFound(t *testing.T) {
+	repo := repositoryMock{}
+	service := newService(&repo, newJournal(), newScenarios())
+	repo.On("Get", "1").Return(nil, mappingNotFound("1"))
+	_, err := service.Get("1")
+	assert.Error(t, err)
+	assert.Equal(t, "mapping_not_found", err.(Error).Code)
+}
+
+func TestUpdateSuccess(t *testing.T) {
+	repo := repositoryMock{}
+	service := newService(&repo, newJournal(), newScenarios())
+	repo.On("Get", "1").Return(&mock{ID: "1"}, nil)
+	repo.On("Save", mocking.MatchedBy(func(aggregate mock) bool {
+		return aggregate.ID == "1" && aggregate.Response.Status == 201
+	})).Return(nil)
+	resp, err := service.Update("1", mockDTO{
+		ID: "other",
+		Request: &requestDTO{
+			URL: conditionDTO{"equal_to": "/test"},
+		},
+		Response: &responseDTO{Status: 201},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "1", resp.ID)
+	repo.AssertExpectations(t)
+}
+
+func TestUpdateNotFound(t *testing.T) {
+	repo := repositoryMock{}
+	service := newService(&repo, newJournal(), newScenarios())
+	repo.On("Get", "1").Return(nil, mappingNotFound("1"))
+	_, err := service.Update("1", mockDTO{})
+	assert.Error(t, err)
+	assert.Equal(t, "mapping_not_found", err.(Error).Code)
+	repo.AssertNotCalled(t, "Save", mocking.Anything)
+}
+
+func TestUpdateInvalidMock(t *testing.T) {
+	repo := repositoryMock{}
+	service := newService(&repo, newJournal(), newScenarios())
+	repo.On("Get", "1").Return(&mock{ID: "1"}, nil)
+	_, err := service.Update("1", mockDTO{})
+	assert.Error(t, err)
+	assert.Equal(t, "invalid_request", err.(Error).Code)
+	repo.AssertNotCalled(t, "Save", mocking.Anything)
+}
+
+func TestDeleteMapping(t *testing.T) {
+	repo := repositoryMock{}
+	service := newService(&repo, newJournal(), newScenarios())
+	repo.On("Get", "1").Return(&mock{ID: "1"}, nil)
+	repo.On("Delete", "1").Return(nil)
+	assert.Nil(t, service.Delete("1"))
+	repo.AssertExpectations(t)
+}
+
+func TestDeleteMappingUnregistersScenario(t *testing.T) {
+	repo := repositoryMock{}
+	scenarios := newScenarios()
+	scenarios.Register("order", "1")
+	service := newService(&repo, newJournal(), scenarios)
+	repo.On("Get", "1").Return(&mock{ID: "1", Scenario: &scenario{name: "order"}}, nil)
+	repo.On("Delete", "1").Return(nil)
+	assert.Nil(t, service.Delete("1"))
+	assert.Empty(t, service.Scenarios())
+}
+
+func TestUpdateMappingUnregistersPreviousScenario(t *testing.T) {
+	repo := repositoryMock{}
+	scenarios := newScenarios()
+	scenarios.Register("order", "1")
+	service := newService(&repo, newJournal(), scenarios)
+	repo.On("Get", "1").Return(&mock{ID: "1", Scenario: &scenario{name: "order"}}, nil)
+	repo.On("Save", mocking.AnythingOfType("mock.mock")).Return(nil)
+	_, err := service.Update("1", mockDTO{
+		Request:  &requestDTO{URL: conditionDTO{"equal_to": "/test"}},
+		Response: &responseDTO{Status: 200},
+		Scenario: &scenarioDTO{Name: "payment"},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []ScenarioState{{Name: "payment", State: scenarioStarted}}, service.Scenarios())
+}
+
+func TestDeleteMappingNotFound(t *testing.T) {
+	repo := repositoryMock{}
+	service := newService(&repo, newJournal(), newScenarios())
+	repo.On("Get", "1").Return(nil, mappingNotFound("1"))
+	assert.Error(t, service.Delete("1"))
+	repo.AssertNotCalled(t, "Delete", "1")
+}
+
+func TestDeleteAllMappings(t *testing.T) {
+	repo := repositoryMock{}
+	service := newService(&repo, newJournal(), newScenarios())
+	repo.On("DeleteAll").Return(nil)
+	assert.Nil(t, service.DeleteAll())
+	repo.AssertExpectations(t)
+}
+
+func TestCountByMapping(t *testing.T) {
+	repo := repositoryMock{}
+	journal := journalMock{}
+	journal.On("GetAll").Return([]journalEntry{
+		newJournalEntry(httpRequest{URL: "/test"}, "1", ""),
+		newJournalEntry(httpRequest{URL: "/test"}, "2", ""),
+		newJournalEntry(httpRequest{URL: "/test"}, "1", ""),
+		newJournalEntry(httpRequest{URL: "/other"}, "", ""),
+	})
+	service := newService(&repo, &journal, newScenarios())
+	assert.Equal(t, 2, service.CountByMapping("1"))
+	assert.Equal(t, 0, service.CountByMapping("3"))
+}
+
+func TestAddRegistersScenario(t *testing.T) {
+	repo := repositoryMock{}
+	scenarios := newScenarios()
+	service := newService(&repo, newJournal(), scenarios)
+	repo.On("Save", mocking.AnythingOfType("mock.mock")).Return(nil)
+	_, err := service.Add(mockDTO{
+		Request:  &requestDTO{URL: conditionDTO{"equal_to": "/orders"}},
+		Response: &responseDTO{Status: 200},
+		Scenario: &scenarioDTO{Name: "order", RequiredState: scenarioStarted, NewState: "created"},
+	})
+	assert.Nil(t, err)
+	state, err := service.ScenarioState("order")
+	assert.Nil(t, err)
+	assert.Equal(t, scenarioStarted, state)
+}
+
+func TestAddInvalidScenario(t *testing.T) {
+	repo := repositoryMock{}
+	service := newService(&repo, newJournal(), newScenarios())
+	_, err := service.Add(mockDTO{
+		Request:  &requestDTO{URL: conditionDTO{"equal_to": "/orders"}},
+		Response: &responseDTO{Status: 200},
+		Scenario: &scenarioDTO{RequiredState: scenarioStarted},
+	})
+	assert.Error(t, err)
+	assert.Equal(t, "the scenario name is required", err.(Error).Cause)
+	repo.AssertNotCalled(t, "Save", mocking.Anything)
+}
+
+func TestMatchScenarioTransitions(t *testing.T) {
+	get := getMethod
+	post := postMethod
+	aggregates := []mock{
+		{
+			ID:       "pending",
+			Request:  requestMatch{URL: &simplexCondition{operator: equal, value: "/order"}, Method: &get},
+			Response: httpResponse{Status: 202},
+			Scenario: &scenario{name: "order", requiredState: scenarioStarted},
+		},
+		{
+			ID:       "pay",
+			Request:  requestMatch{URL: &simplexCondition{operator: equal, value: "/order"}, Method: &post},
+			Response: httpResponse{Status: 201},
+			Scenario: &scenario{name: "order", requiredState: scenarioStarted, newState: "paid"},
+		},
+		{
+			ID:       "done",
+			Request:  requestMatch{URL: &simplexCondition{operator: equal, value: "/order"}, Method: &get},
+			Response: httpResponse{Status: 200},
+			Scenario: &scenario{name: "order", requiredState: "paid"},
+		},
+	}
+	repo := repositoryMock{}
+	scenarios := newScenarios()
+	scenarios.Register("order", "1")
+	service := newService(&repo, newJournal(), scenarios)
+	repo.On("GetAll").Return(aggregates)
+	resp, err := service.Match(httpRequest{URL: "/order", Method: getMethod})
+	assert.Nil(t, err)
+	assert.Equal(t, 202, resp.Status)
+	resp, err = service.Match(httpRequest{URL: "/order", Method: postMethod})
+	assert.Nil(t, err)
+	assert.Equal(t, 201, resp.Status)
+	resp, err = service.Match(httpRequest{URL: "/order", Method: getMethod})
+	assert.Nil(t, err)
+	assert.Equal(t, 200, resp.Status)
+	_, err = service.Match(httpRequest{URL: "/order", Method: postMethod})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `scenario order state "paid" is not "Started"`)
+	service.ResetScenarios()
+	resp, err = service.Match(httpRequest{URL: "/order", Method: getMethod})
+	assert.Nil(t, err)
+	assert.Equal(t, 202, resp.Status)
+}
+
+func TestServiceScenarioStateNotFound(t *testing.T) {
+	repo := repositoryMock{}
+	service := newService(&repo, newJournal(), newScenarios())
+	_, err := service.ScenarioState("order")
+	assert.Error(t, err)
+	assert.Equal(t, "scenario_not_found", err.(Error).Code)
+}
+
+func TestSetScenarioState(t *testing.T) {
+	repo := repositoryMock{}
+	scenarios := newScenarios()
+	scenarios.Register("order", "1")
+	service := newService(&repo, newJournal(), scenarios)
+	assert.Nil(t, service.SetScenarioState("order", "paid"))
+	assert.Equal(t, []ScenarioState{{Name: "order", State: "paid"}}, service.Scenarios())
+	err := service.SetScenarioState("order", "")
+	assert.Error(t, err)
+	assert.Equal(t, "invalid_request", err.(Error).Code)
+}
+
+func TestDeleteAllClearsScenarios(t *testing.T) {
+	repo := repositoryMock{}
+	scenarios := newScenarios()
+	scenarios.Register("order", "1")
+	service := newService(&repo, newJournal(), scenarios)
+	repo.On("DeleteAll").Return(nil)
+	assert.Nil(t, service.DeleteAll())
+	assert.Empty(t, service.Scenarios())
+}
+
+func TestAddInvalidResponses(t *testing.T) {
+	cases := map[string]mockDTO{
+		"the mock could not have response and responses at the same time": {
+			Request:   &requestDTO{URL: conditionDTO{"equal_to": "/test"}},
+			Response:  &responseDTO{Status: 200},
+			Responses: []*responseDTO{{Status: 200}},
+		},
+		"the mock responses could not contain a null": {
+			Request:   &requestDTO{URL: conditionDTO{"equal_to": "/test"}},
+			Responses: []*responseDTO{{Status: 200}, nil},
+		},
+		"the response status is required": {
+			Request:   &requestDTO{URL: conditionDTO{"equal_to": "/test"}},
+			Responses: []*responseDTO{{Status: 200}, {}},
+		},
+		"the responses mode forever is not supported.": {
+			Request:       &requestDTO{URL: conditionDTO{"equal_to": "/test"}},
+			Responses:     []*responseDTO{{Status: 200}},
+			ResponsesMode: "forever",
+		},
+	}
+	for cause, m := range cases {
+		t.Run(cause, func(t *testing.T) {
+			repo := repositoryMock{}
+			service := newService(&repo, newJournal(), newScenarios())
+			_, err := service.Add(m)
+			assert.Error(t, err)
+			assert.Equal(t, cause, err.(Error).Cause)
+			repo.AssertNotCalled(t, "Save", mocking.Anything)
+		})
+	}
+}
+
+func TestMatchResponseSequence(t *testing.T) {
+	aggregates := []mock{
+		{
+			ID:       "1",
+			Request:  requestMatch{URL: &simplexCondition{operator: equal, value: "/test"}},
+			Response: httpResponse{Status: 500},
+			Sequence: newResponseSequence([]httpResponse{{Status: 500}, {Status: 200}}, false),
+		},
+	}
+	repo := repositoryMock{}
+	service := newService(&repo, newJournal(), newScenarios())
+	repo.On("GetAll").Return(aggregates)
+	var statuses []int
+	for i := 0; i < 3; i++ {
+		resp, err := service.Match(httpRequest{URL: "/test"})
+		assert.Nil(t, err)
+		statuses = append(statuses, resp.Status)
+	}
+	assert.Equal(t, []int{500, 200, 200}, statuses)
+}
+
+func TestAddFaultWithoutStatus(t *testing.T) {
+	repo := repositoryMock{}
+	repo.On("Save", mocking.AnythingOfType("mock.mock")).Return(nil)
+	service := newService(&repo, newJournal(), newScenarios())
+	_, err := service.Add(mockDTO{
+		Request:  &requestDTO{URL: conditionDTO{"equal_to": "/test"}},
+		Response: &responseDTO{Fault: "connection_reset_by_peer"},
+	})
+	assert.Nil(t, err)
+	repo.AssertExpectations(t)
+}
@@ -0,0 +1,38 @@
+package mock
+
+type Stub interface {
+	ID() string
+	Verify() Verification
+	Update(resp ...*responseDTO) error
+	Remove() error
+}
+
+type stub struct {
+	id         string
+	definition mockDTO
+	service    Service
+}
+
+func (s *stub) ID() string {
+	return s.id
+}
+
+func (s *stub) Verify() Verification {
+	return &verification{
+		mappingID: s.id,
+		service:   s.service,
+	}
+}
+
+func (s *stub) Update(resp ...*responseDTO) error {
+	mock, err := withResponses(s.definition, resp)
+	if err != nil {
+		return err
+	}
+	_, err = s.service.Update(s.id, mock)
+	return err
+}
+
+func (s *stub) Remove() error {
+	return s.service.Delete(s.id)
+}
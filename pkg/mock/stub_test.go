@@ -0,0 +1,92 @@
+package mock
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	mocking "github.com/stretchr/testify/mock"
+)
+
+func TestThenReturnStub(t *testing.T) {
+	srvMock := serviceMock{}
+	mocker := internalNew(&srvMock)
+	srvMock.On("Add", mocking.AnythingOfType("mock.mockDTO")).Return(&addMockResponse{ID: "123"}, nil)
+	created, err := mocker.When(Request().URLEqualsTo("/inventories").Build()).
+		ThenReturnStub(Response().WithStatus(200).Build())
+	assert.Nil(t, err)
+	assert.Equal(t, "123", created.ID())
+	srvMock.AssertExpectations(t)
+}
+
+func TestThenReturnStubError(t *testing.T) {
+	srvMock := serviceMock{}
+	mocker := internalNew(&srvMock)
+	srvMock.On("Add", mocking.AnythingOfType("mock.mockDTO")).Return(nil, invalidRequest("invalid"))
+	created, err := mocker.When(Request().URLEqualsTo("/inventories").Build()).
+		ThenReturnStub(Response().WithStatus(200).Build())
+	assert.Error(t, err)
+	assert.Nil(t, created)
+}
+
+func TestStubUpdate(t *testing.T) {
+	srvMock := serviceMock{}
+	req := Request().URLEqualsTo("/inventories").Build()
+	created := &stub{id: "123", definition: mockDTO{Request: req}, service: &srvMock}
+	srvMock.On("Update", "123", mocking.MatchedBy(func(dto mockDTO) bool {
+		return dto.Request == req && dto.Response.Status == 500
+	})).Return(&addMockResponse{ID: "123"}, nil)
+	assert.Nil(t, created.Update(Response().WithStatus(500).Build()))
+	srvMock.AssertExpectations(t)
+}
+
+func TestStubUpdateNilResponse(t *testing.T) {
+	srvMock := serviceMock{}
+	created := &stub{id: "123", service: &srvMock}
+	err := created.Update(nil)
+	assert.Error(t, err)
+	assert.Equal(t, "the response builder could not be nil", err.Error())
+	srvMock.AssertNotCalled(t, "Update", mocking.Anything, mocking.Anything)
+}
+
+func TestStubRemove(t *testing.T) {
+	srvMock := serviceMock{}
+	created := &stub{id: "123", service: &srvMock}
+	srvMock.On("Delete", "123").Return(nil)
+	assert.Nil(t, created.Remove())
+	srvMock.AssertExpectations(t)
+}
+
+func TestStubVerify(t *testing.T) {
+	srvMock := serviceMock{}
+	created := &stub{id: "123", service: &srvMock}
+	srvMock.On("CountByMapping", "123").Return(1)
+	assert.Nil(t, created.Verify().Times(1))
+	err := created.Verify().Never()
+	assert.Error(t, err)
+	assert.Equal(t, "expected exactly 0 request(s) served by mapping 123 but received 1", err.Error())
+}
+
+func TestStubLifecycle(t *testing.T) {
+	router, mocker := New()
+	baseURL, err := router.StartEphemeral()
+	assert.Nil(t, err)
+	defer router.Stop(context.Background())
+	created, err := mocker.When(Request().URLEqualsTo("/inventories").Build()).
+		ThenReturnStub(Response().WithStatus(http.StatusOK).Build())
+	assert.Nil(t, err)
+	response, err := http.Get(baseURL + "/inventories")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Nil(t, created.Verify().Times(1))
+	assert.Nil(t, created.Update(Response().WithStatus(http.StatusServiceUnavailable).Build()))
+	response, err = http.Get(baseURL + "/inventories")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, response.StatusCode)
+	assert.Nil(t, created.Verify().Times(2))
+	assert.Nil(t, created.Remove())
+	response, err = http.Get(baseURL + "/inventories")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusNotFound, response.StatusCode)
+}
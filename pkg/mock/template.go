@@ -0,0 +1,147 @@
+package mock
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const randomStringCharset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+type responseTemplate struct {
+	body    *template.Template
+	headers map[string]*template.Template
+}
+
+type templateData struct {
+	Request templateRequest
+}
+
+type templateRequest struct {
+	URL          string
+	Method       string
+	PathSegments []string
+	PathParams   map[string]string
+	Headers      map[string]string
+	Query        map[string]string
+	Body         string
+	JSON         any
+}
+
+var templateFuncs = template.FuncMap{
+	"now":          templateNow,
+	"uuid":         templateUUID,
+	"randomInt":    templateRandomInt,
+	"randomString": templateRandomString,
+	"toJson":       templateToJson,
+}
+
+func newResponseTemplate(body []byte, headers map[string]string) (*responseTemplate, error) {
+	bodyTemplate, err := parseTemplate("body", string(body))
+	if err != nil {
+		return nil, err
+	}
+	headerTemplates := map[string]*template.Template{}
+	for name, value := range headers {
+		headerTemplate, err := parseTemplate(name, value)
+		if err != nil {
+			return nil, err
+		}
+		headerTemplates[name] = headerTemplate
+	}
+	return &responseTemplate{
+		body:    bodyTemplate,
+		headers: headerTemplates,
+	}, nil
+}
+
+func parseTemplate(name string, text string) (*template.Template, error) {
+	parsed, err := template.New(name).Funcs(templateFuncs).Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return nil, invalidRequest(fmt.Sprintf("the response template is invalid: %v", err))
+	}
+	return parsed, nil
+}
+
+func (t *responseTemplate) render(response httpResponse, request httpRequest) (*httpResponse, error) {
+	data := newTemplateData(request)
+	body, err := execute(t.body, data)
+	if err != nil {
+		return nil, err
+	}
+	headers := map[string]string{}
+	for name, headerTemplate := range t.headers {
+		value, err := execute(headerTemplate, data)
+		if err != nil {
+			return nil, err
+		}
+		headers[name] = string(value)
+	}
+	response.Body = body
+	response.Headers = headers
+	response.Template = nil
+	return &response, nil
+}
+
+func execute(t *template.Template, data templateData) ([]byte, error) {
+	buffer := new(bytes.Buffer)
+	err := t.Execute(buffer, data)
+	if err != nil {
+		return nil, templateError(err)
+	}
+	return buffer.Bytes(), nil
+}
+
+func newTemplateData(request httpRequest) templateData {
+	body, _ := parseJSON(request.Body)
+	return templateData{
+		Request: templateRequest{
+			URL:          request.URL,
+			Method:       request.Method,
+			PathSegments: strings.FieldsFunc(request.URL, func(r rune) bool { return r == '/' }),
+			PathParams:   request.PathParameters,
+			Headers:      request.Headers,
+			Query:        request.QueryParameters,
+			Body:         string(request.Body),
+			JSON:         body,
+		},
+	}
+}
+
+func templateNow(layout ...string) string {
+	if len(layout) > 0 {
+		return time.Now().Format(layout[0])
+	}
+	return time.Now().Format(time.RFC3339)
+}
+
+func templateUUID() string {
+	uid, _ := uuid.NewRandom()
+	return uid.String()
+}
+
+func templateRandomInt(min int, max int) int {
+	if max <= min {
+		return min
+	}
+	return min + rand.Intn(max-min+1)
+}
+
+func templateRandomString(length int) string {
+	result := make([]byte, length)
+	for i := range result {
+		result[i] = randomStringCharset[rand.Intn(len(randomStringCharset))]
+	}
+	return string(result)
+}
+
+func templateToJson(value any) (string, error) {
+	data, err := json.Marshal(value)
+	return string(data), err
+}
@@ -0,0 +1,119 @@
+package mock
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func renderTemplate(t *testing.T, body string, request httpRequest) string {
+	responseTemplate, err := newResponseTemplate([]byte(body), nil)
+	require.Nil(t, err)
+	response, err := responseTemplate.render(httpResponse{Status: 200}, request)
+	require.Nil(t, err)
+	return string(response.Body)
+}
+
+func TestTemplateRequestData(t *testing.T) {
+	request := httpRequest{
+		URL:             "/users/123/orders",
+		Method:          postMethod,
+		Headers:         map[string]string{"Accept": "json"},
+		QueryParameters: map[string]string{"page": "2"},
+		Body:            []byte(`{"user":{"name":"pedro"},"tags":["a","b"]}`),
+	}
+	body := renderTemplate(t, `{{.Request.Method}} {{.Request.URL}} {{index .Request.PathSegments 1}} `+
+		`{{.Request.Headers.Accept}} {{.Request.Query.page}} {{.Request.JSON.user.name}} {{index .Request.JSON.tags 1}}`,
+		request)
+	assert.Equal(t, "POST /users/123/orders 123 json 2 pedro b", body)
+}
+
+func TestTemplateJSONKeepsIntegers(t *testing.T) {
+	body := renderTemplate(t, `{{.Request.JSON.id}} {{.Request.JSON.price}} {{toJson .Request.JSON}}`,
+		httpRequest{Body: []byte(`{"id":12345678,"price":10.5}`)})
+	assert.Equal(t, `12345678 10.5 {"id":12345678,"price":10.5}`, body)
+}
+
+func TestTemplateHeaderWithDash(t *testing.T) {
+	body := renderTemplate(t, `{{index .Request.Headers "X-Request-Id"}}`, httpRequest{Headers: map[string]string{"X-Request-Id": "abc"}})
+	assert.Equal(t, "abc", body)
+}
+
+func TestTemplateRawBody(t *testing.T) {
+	body := renderTemplate(t, `{{.Request.Body}}`, httpRequest{Body: []byte("plain text")})
+	assert.Equal(t, "plain text", body)
+}
+
+func TestTemplateHelpers(t *testing.T) {
+	body := renderTemplate(t, `{{uuid}}`, httpRequest{})
+	assert.Regexp(t, regexp.MustCompile(`^[0-9a-f-]{36}$`), body)
+	body = renderTemplate(t, `{{randomInt 5 10}}`, httpRequest{})
+	value, err := strconv.Atoi(body)
+	assert.Nil(t, err)
+	assert.GreaterOrEqual(t, value, 5)
+	assert.LessOrEqual(t, value, 10)
+	body = renderTemplate(t, `{{randomInt 5 5}}`, httpRequest{})
+	assert.Equal(t, "5", body)
+	body = renderTemplate(t, `{{randomString 12}}`, httpRequest{})
+	assert.Regexp(t, regexp.MustCompile(`^[a-zA-Z0-9]{12}$`), body)
+	body = renderTemplate(t, `{{now "2006"}}`, httpRequest{})
+	assert.Equal(t, strconv.Itoa(time.Now().Year()), body)
+	body = renderTemplate(t, `{{now}}`, httpRequest{})
+	_, err = time.Parse(time.RFC3339, body)
+	assert.Nil(t, err)
+	body = renderTemplate(t, `{{toJson .Request.JSON.user}}`, httpRequest{Body: []byte(`{"user":{"id":1}}`)})
+	assert.Equal(t, `{"id":1}`, body)
+}
+
+func TestTemplateHeaders(t *testing.T) {
+	responseTemplate, err := newResponseTemplate(nil, map[string]string{"Location": "/users/{{index .Request.PathSegments 1}}"})
+	assert.Nil(t, err)
+	response, err := responseTemplate.render(httpResponse{Status: 201}, httpRequest{URL: "/users/9"})
+	assert.Nil(t, err)
+	assert.Equal(t, 201, response.Status)
+	assert.Equal(t, "/users/9", response.Headers["Location"])
+	assert.Nil(t, response.Template)
+}
+
+func TestTemplateInvalid(t *testing.T) {
+	_, err := newResponseTemplate([]byte(`{{.Request.URL`), nil)
+	assert.Error(t, err)
+	assert.Equal(t, "invalid_request", err.(Error).Code)
+	_, err = newResponseTemplate(nil, map[string]string{"Location": "{{unknownFunc}}"})
+	assert.Error(t, err)
+	assert.Equal(t, "invalid_request", err.(Error).Code)
+}
+
+func TestTemplateExecutionError(t *testing.T) {
+	responseTemplate, err := newResponseTemplate([]byte(`{{index .Request.PathSegments 5}}`), nil)
+	assert.Nil(t, err)
+	_, err = responseTemplate.render(httpResponse{Status: 200}, httpRequest{URL: "/users"})
+	assert.Error(t, err)
+	assert.Equal(t, "template_error", err.(Error).Code)
+}
+
+func TestTemplateOverHttp(t *testing.T) {
+	router, mocker := New()
+	baseURL, err := router.StartEphemeral()
+	assert.Nil(t, err)
+	defer router.Stop(context.Background())
+	err = mocker.When(Request().URLPattern("^/users/.*").Build()).ThenReturn(
+		Response().
+			WithStatus(http.StatusOK).
+			WithBodyAsString(`{"id":"{{index .Request.PathSegments 1}}","page":"{{.Request.Query.page}}"}`).
+			WithTemplate().
+			Build(),
+	)
+	assert.Nil(t, err)
+	response, err := http.Get(baseURL + "/users/42?page=3")
+	assert.Nil(t, err)
+	body, _ := io.ReadAll(response.Body)
+	assert.Equal(t, `{"id":"42","page":"3"}`, string(body))
+}
@@ -0,0 +1,91 @@
+package mock
+
+import (
+	"fmt"
+	"strings"
+)
+
+type urlTemplateSegment struct {
+	literal   string
+	parameter string
+}
+
+type urlTemplate []urlTemplateSegment
+
+func parseURLTemplate(value string) (urlTemplate, error) {
+	if !strings.HasPrefix(value, "/") {
+		return nil, invalidURLTemplate(value)
+	}
+	var template urlTemplate
+	names := map[string]bool{}
+	for _, segment := range strings.Split(value[1:], "/") {
+		if !strings.ContainsAny(segment, "{}") {
+			template = append(template, urlTemplateSegment{literal: segment})
+			continue
+		}
+		name := strings.TrimSuffix(strings.TrimPrefix(segment, "{"), "}")
+		if len(name)+2 != len(segment) || name == "" || strings.ContainsAny(name, "{}") || names[name] {
+			return nil, invalidURLTemplate(value)
+		}
+		names[name] = true
+		template = append(template, urlTemplateSegment{parameter: name})
+	}
+	return template, nil
+}
+
+func invalidURLTemplate(value string) error {
+	return invalidRequest(fmt.Sprintf("the url template %s is invalid.", value))
+}
+
+func (template urlTemplate) extract(path string) (map[string]string, bool) {
+	if !strings.HasPrefix(path, "/") {
+		return nil, false
+	}
+	segments := strings.Split(path[1:], "/")
+	if len(segments) != len(template) {
+		return nil, false
+	}
+	parameters := map[string]string{}
+	for i, segment := range template {
+		if segment.parameter == "" {
+			if segment.literal != segments[i] {
+				return nil, false
+			}
+			continue
+		}
+		if segments[i] == "" {
+			return nil, false
+		}
+		parameters[segment.parameter] = segments[i]
+	}
+	return parameters, true
+}
+
+func (template urlTemplate) parameters() []string {
+	var names []string
+	for _, segment := range template {
+		if segment.parameter != "" {
+			names = append(names, segment.parameter)
+		}
+	}
+	return names
+}
+
+func (c *simplexCondition) pathParameters(path string) map[string]string {
+	if c == nil || c.operator == not {
+		return nil
+	}
+	if c.operator == urlTemplateMatch {
+		if c.template == nil {
+			return nil
+		}
+		parameters, _ := c.template.extract(path)
+		return parameters
+	}
+	for _, condition := range c.conditions {
+		if parameters := condition.pathParameters(path); parameters != nil {
+			return parameters
+		}
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package mock
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestURLTemplateExtract(t *testing.T) {
+	template, err := parseURLTemplate("/users/{id}/orders/{orderId}")
+	require.Nil(t, err)
+	assert.Equal(t, []string{"id", "orderId"}, template.parameters())
+	parameters, matched := template.extract("/users/12/orders/ab-3")
+	assert.True(t, matched)
+	assert.Equal(t, map[string]string{"id": "12", "orderId": "ab-3"}, parameters)
+	for _, path := range []string{"/users/12/orders", "/users/12/orders/3/items", "/clients/12/orders/3", "/users//orders/3", "users/1/orders/2"} {
+		_, matched = template.extract(path)
+		assert.False(t, matched, path)
+	}
+}
+
+func TestURLTemplateInvalid(t *testing.T) {
+	for _, value := range []string{"users/{id}", "/users/{id", "/users/{}", "/users/id}", "/users/{id}.json", "/{id}/{id}"} {
+		_, err := parseURLTemplate(value)
+		require.Error(t, err, value)
+		assert.Equal(t, "the url template "+value+" is invalid.", err.(Error).Description)
+	}
+}
+
+func TestURLTemplateWithPathParameterConditions(t *testing.T) {
+	dto := mockDTO{
+		Request: Request().URLTemplate("/users/{id}/orders/{orderId}").
+			PathParamPatternIs("id", "^[0-9]+$").
+			PathParamIsEqualTo("orderId", "7").Build(),
+		Response: Response().WithStatus(200).Build(),
+	}
+	aggregate, err := dto.toAggregate()
+	require.Nil(t, err)
+	assert.True(t, aggregate.Request.IsExpected(httpRequest{URL: "/users/12/orders/7"}))
+	assert.False(t, aggregate.Request.IsExpected(httpRequest{URL: "/users/ab/orders/7"}))
+	assert.False(t, aggregate.Request.IsExpected(httpRequest{URL: "/users/12/orders/8"}))
+	assert.ElementsMatch(t, []fieldMatch{
+		{Field: "url", Matched: true},
+		{Field: "path parameter id", Reason: `path parameter id "ab" does not match pattern "^[0-9]+$"`},
+		{Field: "path parameter orderId", Matched: true},
+	}, aggregate.Request.evaluate(httpRequest{URL: "/users/ab/orders/7"}))
+}
+
+func TestURLTemplatePathParameterNotDefined(t *testing.T) {
+	dto := mockDTO{
+		Request:  Request().URLEqualsTo("/users/1").PathParamIsEqualTo("id", "1").Build(),
+		Response: Response().WithStatus(200).Build(),
+	}
+	_, err := dto.toAggregate()
+	require.Error(t, err)
+	assert.Equal(t, "the path parameter id is not defined in the url template.", err.(Error).Description)
+}
@@ -0,0 +1,327 @@
+package mock
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+type xmlNode struct {
+	name     xml.Name
+	attrs    map[xml.Name]string
+	children []*xmlNode
+	text     string
+}
+
+type xPathStep struct {
+	descendant bool
+	name       string
+	attribute  bool
+	text       bool
+	predicates []xPathPredicate
+}
+
+type xPathPredicate struct {
+	position  int
+	attribute string
+	value     *string
+}
+
+type xPath []xPathStep
+
+func parseXML(body []byte) (*xmlNode, bool) {
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+	document := &xmlNode{}
+	stack := []*xmlNode{document}
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, false
+		}
+		current := stack[len(stack)-1]
+		switch t := token.(type) {
+		case xml.StartElement:
+			node := &xmlNode{name: t.Name, attrs: map[xml.Name]string{}}
+			for _, attr := range t.Attr {
+				if attr.Name.Space == "xmlns" || (attr.Name.Space == "" && attr.Name.Local == "xmlns") {
+					continue
+				}
+				node.attrs[attr.Name] = attr.Value
+			}
+			current.children = append(current.children, node)
+			stack = append(stack, node)
+		case xml.EndElement:
+			current.text = strings.TrimSpace(current.text)
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			current.text += string(t)
+		}
+	}
+	if len(stack) != 1 || len(document.children) != 1 || strings.TrimSpace(document.text) != "" {
+		return nil, false
+	}
+	return document, true
+}
+
+func (node *xmlNode) matches(body string) bool {
+	if node == nil {
+		return false
+	}
+	actual, valid := parseXML([]byte(body))
+	return valid && node.equal(actual)
+}
+
+func (node *xmlNode) equal(other *xmlNode) bool {
+	if node.name != other.name || node.text != other.text ||
+		len(node.attrs) != len(other.attrs) || len(node.children) != len(other.children) {
+		return false
+	}
+	for name, value := range node.attrs {
+		if otherValue, exists := other.attrs[name]; !exists || otherValue != value {
+			return false
+		}
+	}
+	for i := range node.children {
+		if !node.children[i].equal(other.children[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (node *xmlNode) stringValue() string {
+	value := node.text
+	for _, child := range node.children {
+		value += child.stringValue()
+	}
+	return value
+}
+
+func (node *xmlNode) descendants() []*xmlNode {
+	var nodes []*xmlNode
+	for _, child := range node.children {
+		nodes = append(nodes, child)
+		nodes = append(nodes, child.descendants()...)
+	}
+	return nodes
+}
+
+func (node *xmlNode) attribute(name string) (string, bool) {
+	for attrName, value := range node.attrs {
+		if attrName.Local == localName(name) {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+func compileXPath(expression string) (xPath, error) {
+	if !strings.HasPrefix(expression, "/") {
+		return nil, invalidXPath(expression)
+	}
+	var path xPath
+	rest := expression
+	for rest != "" {
+		step := xPathStep{}
+		switch {
+		case strings.HasPrefix(rest, "//"):
+			step.descendant = true
+			rest = rest[2:]
+		case strings.HasPrefix(rest, "/"):
+			rest = rest[1:]
+		default:
+			return nil, invalidXPath(expression)
+		}
+		end := splitXPathStep(rest)
+		if end < 0 {
+			return nil, invalidXPath(expression)
+		}
+		if err := step.parse(rest[:end], expression); err != nil {
+			return nil, err
+		}
+		rest = rest[end:]
+		if (step.attribute || step.text) && rest != "" {
+			return nil, invalidXPath(expression)
+		}
+		path = append(path, step)
+	}
+	return path, nil
+}
+
+func splitXPathStep(rest string) int {
+	depth := 0
+	for i, char := range rest {
+		switch char {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case '/':
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	if depth != 0 {
+		return -1
+	}
+	return len(rest)
+}
+
+func (step *xPathStep) parse(raw string, expression string) error {
+	name := raw
+	if index := strings.Index(raw, "["); index >= 0 {
+		name = raw[:index]
+		for predicates := raw[index:]; predicates != ""; {
+			end := strings.Index(predicates, "]")
+			if !strings.HasPrefix(predicates, "[") || end < 0 {
+				return invalidXPath(expression)
+			}
+			predicate, err := parseXPathPredicate(strings.TrimSpace(predicates[1:end]), expression)
+			if err != nil {
+				return err
+			}
+			step.predicates = append(step.predicates, predicate)
+			predicates = predicates[end+1:]
+		}
+	}
+	switch {
+	case name == "":
+		return invalidXPath(expression)
+	case name == "text()":
+		step.text = true
+	case strings.HasPrefix(name, "@"):
+		step.attribute = true
+		step.name = name[1:]
+	default:
+		step.name = name
+	}
+	if (step.text || step.attribute) && (step.descendant || len(step.predicates) > 0 || step.name == "" && step.attribute) {
+		return invalidXPath(expression)
+	}
+	return nil
+}
+
+func parseXPathPredicate(raw string, expression string) (xPathPredicate, error) {
+	if position, err := strconv.Atoi(raw); err == nil && position > 0 {
+		return xPathPredicate{position: position}, nil
+	}
+	if !strings.HasPrefix(raw, "@") {
+		return xPathPredicate{}, invalidXPath(expression)
+	}
+	attribute, value, found := strings.Cut(raw[1:], "=")
+	predicate := xPathPredicate{attribute: strings.TrimSpace(attribute)}
+	if predicate.attribute == "" {
+		return xPathPredicate{}, invalidXPath(expression)
+	}
+	if found {
+		value = strings.TrimSpace(value)
+		if len(value) < 2 || (value[0] != '\'' && value[0] != '"') || value[len(value)-1] != value[0] {
+			return xPathPredicate{}, invalidXPath(expression)
+		}
+		value = value[1 : len(value)-1]
+		predicate.value = &value
+	}
+	return predicate, nil
+}
+
+func invalidXPath(expression string) error {
+	return invalidRequest(fmt.Sprintf("the xpath %s is invalid.", expression))
+}
+
+func (path xPath) evaluate(document *xmlNode) []string {
+	nodes := []*xmlNode{document}
+	for _, step := range path {
+		if step.attribute || step.text {
+			return step.values(nodes)
+		}
+		var next []*xmlNode
+		for _, node := range nodes {
+			next = append(next, step.apply(node)...)
+		}
+		nodes = next
+	}
+	var values []string
+	for _, node := range nodes {
+		values = append(values, node.stringValue())
+	}
+	return values
+}
+
+func (step xPathStep) apply(node *xmlNode) []*xmlNode {
+	if !step.descendant {
+		return step.selectChildren(node)
+	}
+	selected := map[*xmlNode]bool{}
+	for _, parent := range append([]*xmlNode{node}, node.descendants()...) {
+		for _, child := range step.selectChildren(parent) {
+			selected[child] = true
+		}
+	}
+	var nodes []*xmlNode
+	for _, descendant := range node.descendants() {
+		if selected[descendant] {
+			nodes = append(nodes, descendant)
+		}
+	}
+	return nodes
+}
+
+func (step xPathStep) selectChildren(parent *xmlNode) []*xmlNode {
+	var nodes []*xmlNode
+	for _, child := range parent.children {
+		if step.name == "*" || child.name.Local == localName(step.name) {
+			nodes = append(nodes, child)
+		}
+	}
+	for _, predicate := range step.predicates {
+		nodes = predicate.filter(nodes)
+	}
+	return nodes
+}
+
+func (step xPathStep) values(nodes []*xmlNode) []string {
+	var values []string
+	for _, node := range nodes {
+		if step.text {
+			if node.text != "" {
+				values = append(values, node.text)
+			}
+			continue
+		}
+		if value, exists := node.attribute(step.name); exists {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+func (predicate xPathPredicate) filter(nodes []*xmlNode) []*xmlNode {
+	if predicate.position > 0 {
+		if predicate.position > len(nodes) {
+			return nil
+		}
+		return nodes[predicate.position-1 : predicate.position]
+	}
+	var filtered []*xmlNode
+	for _, node := range nodes {
+		value, exists := node.attribute(predicate.attribute)
+		if exists && (predicate.value == nil || *predicate.value == value) {
+			filtered = append(filtered, node)
+		}
+	}
+	return filtered
+}
+
+func localName(name string) string {
+	if index := strings.LastIndex(name, ":"); index >= 0 {
+		return name[index+1:]
+	}
+	return name
+}
@@ -0,0 +1,116 @@
+package mock
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const soapEnvelope = `<?xml version="1.0"?>
+<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/" xmlns:u="urn:users">
+  <soap:Body>
+    <u:GetUser version="2">
+      <u:id>42</u:id>
+      <u:roles>
+        <u:role kind="main">admin</u:role>
+        <u:role kind="extra">auditor</u:role>
+      </u:roles>
+    </u:GetUser>
+  </soap:Body>
+</soap:Envelope>`
+
+func evaluateXPath(t *testing.T, expression string) []string {
+	path, err := compileXPath(expression)
+	require.Nil(t, err)
+	document, valid := parseXML([]byte(soapEnvelope))
+	require.True(t, valid)
+	return path.evaluate(document)
+}
+
+func xmlEqualsPredicate(expected string, actual string) bool {
+	condition, _ := compileCondition(equalToXML, expected)
+	return condition.test(actual)
+}
+
+func TestXMLEquals(t *testing.T) {
+	assert.True(t, xmlEqualsPredicate(`<a x="1" y="2"><b>text</b></a>`, "<a y=\"2\"  x=\"1\">\n  <b> text </b>\n</a>"))
+	assert.True(t, xmlEqualsPredicate(`<p:a xmlns:p="urn:x"/>`, `<q:a xmlns:q="urn:x"></q:a>`))
+	assert.True(t, xmlEqualsPredicate(`<a><!-- comment --><b/></a>`, `<?xml version="1.0"?><a><b/></a>`))
+	assert.False(t, xmlEqualsPredicate(`<a x="1"/>`, `<a x="2"/>`))
+	assert.False(t, xmlEqualsPredicate(`<a><b/><c/></a>`, `<a><c/><b/></a>`))
+	assert.False(t, xmlEqualsPredicate(`<p:a xmlns:p="urn:x"/>`, `<p:a xmlns:p="urn:y"/>`))
+	assert.False(t, xmlEqualsPredicate(`<a>1</a>`, `<a>2</a>`))
+	assert.False(t, xmlEqualsPredicate(`<a/>`, `<a>`))
+	assert.False(t, xmlEqualsPredicate(`<a/>`, `<a/><b/>`))
+}
+
+func TestXPathEvaluate(t *testing.T) {
+	assert.Equal(t, []string{"42"}, evaluateXPath(t, "/Envelope/Body/GetUser/id"))
+	assert.Equal(t, []string{"42"}, evaluateXPath(t, "/soap:Envelope/soap:Body/u:GetUser/u:id/text()"))
+	assert.Equal(t, []string{"42"}, evaluateXPath(t, "//id"))
+	assert.Equal(t, []string{"2"}, evaluateXPath(t, "//GetUser/@version"))
+	assert.Equal(t, []string{"admin", "auditor"}, evaluateXPath(t, "//roles/role"))
+	assert.Equal(t, []string{"auditor"}, evaluateXPath(t, "//roles/role[2]"))
+	assert.Equal(t, []string{"admin"}, evaluateXPath(t, "//role[@kind='main']"))
+	assert.Equal(t, []string{"main", "extra"}, evaluateXPath(t, "//role[@kind]/@kind"))
+	assert.Equal(t, []string{"42"}, evaluateXPath(t, "/*/*/*/id"))
+	assert.Equal(t, []string{"42adminauditor"}, evaluateXPath(t, "//GetUser"))
+	assert.Nil(t, evaluateXPath(t, "//missing"))
+	assert.Nil(t, evaluateXPath(t, "//role[3]"))
+}
+
+func TestXPathDescendantPositionIsPerParent(t *testing.T) {
+	document, valid := parseXML([]byte(`<r><a><b>1</b><b>2</b></a><a><b>3</b><c><b>4</b></c></a></r>`))
+	require.True(t, valid)
+	path, err := compileXPath("//b[1]")
+	require.Nil(t, err)
+	assert.Equal(t, []string{"1", "3", "4"}, path.evaluate(document))
+	path, err = compileXPath("//b")
+	require.Nil(t, err)
+	assert.Equal(t, []string{"1", "2", "3", "4"}, path.evaluate(document))
+	path, err = compileXPath("/r//b[2]")
+	require.Nil(t, err)
+	assert.Equal(t, []string{"2"}, path.evaluate(document))
+}
+
+func TestXPathInvalidExpression(t *testing.T) {
+	for _, expression := range []string{"Envelope", "/", "/a//", "/a[", "/a[x]", "/@id/b", "/a[@k=v]", "/a/text()[1]"} {
+		_, err := compileXPath(expression)
+		require.Error(t, err, expression)
+		assert.Equal(t, "the xpath "+expression+" is invalid.", err.(Error).Description)
+	}
+}
+
+func TestXPathConditionsMatch(t *testing.T) {
+	conditions, err := buildXPathConditions(map[string]conditionDTO{
+		"//id":               {"equal_to": "42"},
+		"//role[@kind]":      {"pattern": "^aud"},
+		"//GetUser/@version": {"exists": ""},
+	})
+	require.Nil(t, err)
+	reqMatch := requestMatch{BodyXPath: conditions}
+	assert.True(t, reqMatch.IsExpected(httpRequest{Body: []byte(soapEnvelope)}))
+	assert.False(t, reqMatch.IsExpected(httpRequest{Body: []byte(`<GetUser version="1"><id>42</id></GetUser>`)}))
+	assert.False(t, reqMatch.IsExpected(httpRequest{Body: []byte(`{"id":42}`)}))
+}
+
+func TestXPathConditionsEvaluate(t *testing.T) {
+	conditions, err := buildXPathConditions(map[string]conditionDTO{"//id": {"equal_to": "7"}})
+	require.Nil(t, err)
+	reqMatch := requestMatch{BodyXPath: conditions}
+	assert.Equal(t, []fieldMatch{{
+		Field:  "body xpath //id",
+		Reason: `body xpath //id does not match equal_to "7"`,
+	}}, reqMatch.evaluate(httpRequest{Body: []byte(soapEnvelope)}))
+	assert.Equal(t, "body xpath //id is missing", reqMatch.evaluate(httpRequest{Body: []byte(`<a/>`)})[0].Reason)
+	assert.Equal(t, "body is not a valid xml", reqMatch.evaluate(httpRequest{Body: []byte(`nope`)})[0].Reason)
+}
+
+func TestBuildBodyConditionXML(t *testing.T) {
+	condition, err := buildBodyCondition(conditionDTO{"equal_to_xml": "<a/>"})
+	require.Nil(t, err)
+	assert.True(t, condition.test("<a></a>"))
+	_, err = buildBodyCondition(conditionDTO{"equal_to_xml": "<a>"})
+	assert.Equal(t, "the value <a> of the operator equal_to_xml is not a valid xml.", err.(Error).Description)
+}